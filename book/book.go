@@ -0,0 +1,120 @@
+// Package book has opening books: the plies known to be good in the first
+// positions of a game, so they don't have to be found by searching.
+package book
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/internal/random"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+// A candidate move in a position of the book
+type Entry struct {
+	Move pdn.Move
+	// Relative chance of the move being played, entries with weight 0 are never played
+	Weight int
+}
+
+// A Book has the candidate moves of each position, keyed by the hash of the position
+// (see core.Game.Hash). Moves are stored as in PDN, so they're checked to be legal
+// when the book is probed, in case two positions have the same hash.
+type Book struct {
+	variant   c.Variant
+	positions map[uint64][]Entry
+}
+
+func New(variant c.Variant) *Book {
+	return &Book{
+		variant:   variant,
+		positions: make(map[uint64][]Entry),
+	}
+}
+
+func (b *Book) Variant() c.Variant {
+	return b.variant
+}
+
+// Number of positions in the book
+func (b *Book) Len() int {
+	return len(b.positions)
+}
+
+// Adds weight to the entry of the ply in the current position of the game, adding the entry if needed
+func (b *Book) Add(g *c.Game, p c.Ply, weight int) error {
+	if g.Variant().Name != b.variant.Name {
+		return fmt.Errorf("book: game of variant %v in a book of %v", g.Variant(), b.variant)
+	}
+	m, err := pdn.MoveFromPly(g, p)
+	if err != nil {
+		return fmt.Errorf("book: %w", err)
+	}
+	b.add(g.Hash(), m, weight)
+	return nil
+}
+
+func (b *Book) add(key uint64, m pdn.Move, weight int) {
+	entries := b.positions[key]
+	for i, e := range entries {
+		if e.Move.String() == m.String() {
+			entries[i].Weight += weight
+			return
+		}
+	}
+	b.positions[key] = append(entries, Entry{m, weight})
+}
+
+// Entries of the current position of the game, from the highest weight to the lowest
+func (b *Book) Entries(g *c.Game) []Entry {
+	if g.Variant().Name != b.variant.Name {
+		return nil
+	}
+	return sortedEntries(b.positions[g.Hash()])
+}
+
+func sortedEntries(entries []Entry) []Entry {
+	if len(entries) == 0 {
+		return nil
+	}
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Weight > sorted[j].Weight
+	})
+	return sorted
+}
+
+// Picks one of the legal plies of the book in the current position of the game at random,
+// each one with a chance proportional to its weight. Uses the global source of math/rand if r is nil.
+func (b *Book) Probe(g *c.Game, r *rand.Rand) (c.Ply, bool) {
+	var plies []c.Ply
+	var weights []int
+	total := 0
+	for _, e := range b.Entries(g) {
+		if e.Weight <= 0 {
+			continue
+		}
+		p, err := pdn.PlyFromMove(g, e.Move)
+		if err != nil {
+			continue
+		}
+		plies = append(plies, p)
+		weights = append(weights, e.Weight)
+		total += e.Weight
+	}
+	if total == 0 {
+		return nil, false
+	}
+
+	n := random.Or(r).Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return plies[i], true
+		}
+		n -= w
+	}
+	return plies[len(plies)-1], true
+}
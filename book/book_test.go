@@ -0,0 +1,92 @@
+package book
+
+import (
+	"math/rand"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+func TestAddEntries(t *testing.T) {
+	g := c.NewGame()
+	b := New(c.BrazilianVariant)
+	plies := g.Plies()
+	for _, add := range []struct {
+		ply    c.Ply
+		weight int
+	}{{plies[1], 1}, {plies[0], 3}, {plies[1], 3}, {plies[0], 2}} {
+		if err := b.Add(g, add.ply, add.weight); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.Len() != 1 {
+		t.Errorf("expected 1 position, got %d", b.Len())
+	}
+
+	entries := b.Entries(g)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", entries)
+	}
+	for i, want := range []struct {
+		ply    c.Ply
+		weight int
+	}{{plies[0], 5}, {plies[1], 4}} {
+		p, err := pdn.PlyFromMove(g, entries[i].Move)
+		if err != nil || !p.Equals(want.ply) || entries[i].Weight != want.weight {
+			t.Errorf("entry %d: wanted %v with weight %d, got %v", i, want.ply, want.weight, entries[i])
+		}
+	}
+
+	if err := b.Add(c.NewCustomGame(c.ItalianVariant, nil, c.WhiteColor), plies[0], 1); err == nil {
+		t.Errorf("expected an error adding a game of another variant")
+	}
+	if err := b.Add(g, c.Ply{c.MakeMoveInstruction(0, 0, 4, 4)}, 1); err == nil {
+		t.Errorf("expected an error adding an illegal ply")
+	}
+	if entries := b.Entries(c.NewCustomGame(c.RussianVariant, nil, c.WhiteColor)); entries != nil {
+		t.Errorf("expected no entries for a game of another variant, got %v", entries)
+	}
+}
+
+func TestProbe(t *testing.T) {
+	g := c.NewGame()
+	b := New(c.BrazilianVariant)
+	plies := g.Plies()
+	b.Add(g, plies[0], 4)
+	b.Add(g, plies[1], 1)
+	b.Add(g, plies[2], 0)
+	// as if another position had the same hash
+	b.add(g.Hash(), pdn.Move{Squares: []int{1, 5}}, 100)
+
+	r := rand.New(rand.NewSource(1))
+	counts := make([]int, len(plies))
+	for i := 0; i < 1000; i++ {
+		p, ok := b.Probe(g, r)
+		if !ok {
+			t.Fatalf("expected a ply from the book")
+		}
+		found := false
+		for j, q := range plies {
+			if p.Equals(q) {
+				counts[j]++
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("book gave an illegal ply %v", p)
+		}
+	}
+	if counts[2] != 0 || counts[0]+counts[1] != 1000 || counts[0] < 2*counts[1] {
+		t.Errorf("plies not played according to their weights: %v", counts)
+	}
+
+	if _, ok := b.Probe(g, nil); !ok {
+		t.Errorf("expected a ply using the global random source")
+	}
+
+	g.DoPly(plies[0])
+	if p, ok := b.Probe(g, r); ok {
+		t.Errorf("expected no ply out of the book, got %v", p)
+	}
+}
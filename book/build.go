@@ -0,0 +1,118 @@
+package book
+
+import (
+	"fmt"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+// Points a move gets each time it's played in a game, by the result of the game for the player who played it
+const (
+	WinPoints  = 2
+	DrawPoints = 1
+	LossPoints = 0
+)
+
+type moveStats struct {
+	move   pdn.Move
+	games  int
+	points int
+}
+
+// A Builder makes a book out of the first plies of a collection of games.
+// Each move gets a weight according to how the games it was played in ended
+// (see WinPoints), so the moves that did well are played more often.
+type Builder struct {
+	variant  c.Variant
+	maxPlies int
+	stats    map[uint64][]*moveStats
+}
+
+// The book will have the positions of the first maxPlies plies of each game
+func NewBuilder(variant c.Variant, maxPlies int) *Builder {
+	return &Builder{
+		variant:  variant,
+		maxPlies: maxPlies,
+		stats:    make(map[uint64][]*moveStats),
+	}
+}
+
+// Adds the first plies of the game. Games of other variants, or with illegal moves, are not added.
+func (bd *Builder) AddGame(game *pdn.Game) error {
+	v, err := game.Variant()
+	if err != nil {
+		return fmt.Errorf("book: %w", err)
+	}
+	if v.Name != bd.variant.Name {
+		return fmt.Errorf("book: game of variant %v, building a book of %v", v, bd.variant)
+	}
+
+	g, err := game.Start()
+	if err != nil {
+		return fmt.Errorf("book: %w", err)
+	}
+
+	type played struct {
+		key   uint64
+		move  pdn.Move
+		color c.Color
+	}
+	var moves []played
+	for i, m := range game.Moves {
+		if i >= bd.maxPlies {
+			break
+		}
+		p, err := pdn.PlyFromMove(g, m)
+		if err != nil {
+			return fmt.Errorf("book: move %d (%v): %w", i+1, m, err)
+		}
+		// written the same way for every game, e.g. without unneeded intermediate squares
+		m, _ = pdn.MoveFromPly(g, p)
+		moves = append(moves, played{g.Hash(), m, g.ToPlay()})
+		g.DoPly(p)
+	}
+
+	for _, p := range moves {
+		bd.record(p.key, p.move, points(game.Result, p.color))
+	}
+	return nil
+}
+
+func points(result string, color c.Color) int {
+	switch {
+	case result == pdn.WhiteWinResult && color == c.WhiteColor,
+		result == pdn.BlackWinResult && color == c.BlackColor:
+		return WinPoints
+	case result == pdn.WhiteWinResult, result == pdn.BlackWinResult:
+		return LossPoints
+	default:
+		// draws, and games without a result
+		return DrawPoints
+	}
+}
+
+func (bd *Builder) record(key uint64, m pdn.Move, points int) {
+	for _, s := range bd.stats[key] {
+		if s.move.String() == m.String() {
+			s.games++
+			s.points += points
+			return
+		}
+	}
+	bd.stats[key] = append(bd.stats[key], &moveStats{m, 1, points})
+}
+
+// Makes the book with the moves played in at least minGames of the games added.
+// Moves that were never played in a game that wasn't lost are left out too.
+func (bd *Builder) Book(minGames int) *Book {
+	b := New(bd.variant)
+	for key, stats := range bd.stats {
+		for _, s := range stats {
+			if s.games >= minGames && s.points > 0 {
+				b.add(key, s.move, s.points)
+			}
+		}
+	}
+	return b
+}
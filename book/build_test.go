@@ -0,0 +1,112 @@
+package book
+
+import (
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+const collection = `
+[GameType "21"]
+1. 11-15 24-20 2. 8-11 0-1
+
+[GameType "21"]
+1. 11-15 23-19 1/2-1/2
+
+[GameType "21"]
+1. 9-13 1-0
+`
+
+func buildBook(t *testing.T, maxPlies, minGames int) *Book {
+	t.Helper()
+	games, err := pdn.ReadString(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bd := NewBuilder(c.AmericanVariant, maxPlies)
+	for _, g := range games {
+		if err := bd.AddGame(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bd.Book(minGames)
+}
+
+// Plays the moves from the start and returns the entries of the position reached
+func entriesAfter(t *testing.T, b *Book, moves ...string) map[string]int {
+	t.Helper()
+	g := c.NewCustomGame(c.AmericanVariant, nil, c.BlackColor)
+	for _, s := range moves {
+		m, _ := pdn.ParseMove(s)
+		p, err := pdn.PlyFromMove(g, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.DoPly(p)
+	}
+	entries := make(map[string]int)
+	for _, e := range b.Entries(g) {
+		entries[e.Move.String()] = e.Weight
+	}
+	return entries
+}
+
+func assertEntries(t *testing.T, got map[string]int, want map[string]int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("wanted entries %v, got %v", want, got)
+		return
+	}
+	for m, w := range want {
+		if got[m] != w {
+			t.Errorf("wanted entries %v, got %v", want, got)
+			return
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	b := buildBook(t, 10, 1)
+	// 9-13 and 24-20 were only played by the players who lost
+	assertEntries(t, entriesAfter(t, b), map[string]int{"11-15": WinPoints + DrawPoints})
+	assertEntries(t, entriesAfter(t, b, "11-15"), map[string]int{"23-19": DrawPoints})
+	assertEntries(t, entriesAfter(t, b, "11-15", "24-20"), map[string]int{"8-11": WinPoints})
+	if b.Len() != 3 {
+		t.Errorf("expected 3 positions, got %d", b.Len())
+	}
+
+	b = buildBook(t, 10, 2)
+	assertEntries(t, entriesAfter(t, b), map[string]int{"11-15": WinPoints + DrawPoints})
+	if b.Len() != 1 {
+		t.Errorf("expected only the moves played twice, got %d positions", b.Len())
+	}
+
+	b = buildBook(t, 1, 1)
+	if b.Len() != 1 {
+		t.Errorf("expected only the first ply of each game, got %d positions", b.Len())
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []string{
+		// international, without a GameType
+		"1. 32-28 *",
+		`[GameType "99"] 1. 11-15 *`,
+		`[GameType "21"] [FEN "W:Wxx:B1"] 1. 11-15 *`,
+		`[GameType "21"] 1. 11-15 24-20 2. 1-2 *`,
+	}
+	for _, test := range tests {
+		games, err := pdn.ReadString(test)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bd := NewBuilder(c.AmericanVariant, 10)
+		if err := bd.AddGame(games[0]); err == nil {
+			t.Errorf("%s: expected an error", test)
+		}
+		if b := bd.Book(1); b.Len() != 0 {
+			t.Errorf("%s: expected nothing added, got %d positions", test, b.Len())
+		}
+	}
+}
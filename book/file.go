@@ -0,0 +1,99 @@
+package book
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+// Books are written as text. The first line names the variant, e.g.
+//
+//	variant brazilian
+//
+// and each of the other lines has the hash of a position in hexadecimal,
+// followed by its moves and their weights:
+//
+//	3f2a9c0d11e4b7a0 11-15 12 9-13 5
+//
+// Empty lines and lines starting with # are ignored.
+
+func Write(w io.Writer, b *Book) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "variant %s\n", b.variant.Name)
+
+	keys := make([]uint64, 0, len(b.positions))
+	for key := range b.positions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for _, key := range keys {
+		fmt.Fprintf(bw, "%016x", key)
+		for _, e := range sortedEntries(b.positions[key]) {
+			fmt.Fprintf(bw, " %v %d", e.Move, e.Weight)
+		}
+		bw.WriteByte('\n')
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("book: %w", err)
+	}
+	return nil
+}
+
+func Read(r io.Reader) (*Book, error) {
+	var b *Book
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if b == nil {
+			if len(fields) != 2 || fields[0] != "variant" {
+				return nil, fmt.Errorf("book: line %d: expected the variant", line)
+			}
+			v, ok := c.VariantFromName(fields[1])
+			if !ok {
+				return nil, fmt.Errorf("book: line %d: unknown variant %q", line, fields[1])
+			}
+			b = New(v)
+			continue
+		}
+
+		key, err := strconv.ParseUint(fields[0], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("book: line %d: invalid position hash %q", line, fields[0])
+		}
+		if len(fields) == 1 || len(fields)%2 == 0 {
+			return nil, fmt.Errorf("book: line %d: expected pairs of moves and weights", line)
+		}
+		for i := 1; i < len(fields); i += 2 {
+			m, err := pdn.ParseMove(fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("book: line %d: %w", line, err)
+			}
+			weight, err := strconv.Atoi(fields[i+1])
+			if err != nil || weight < 0 {
+				return nil, fmt.Errorf("book: line %d: invalid weight %q", line, fields[i+1])
+			}
+			b.add(key, m, weight)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("book: %w", err)
+	}
+	if b == nil {
+		return nil, fmt.Errorf("book: missing the variant")
+	}
+	return b, nil
+}
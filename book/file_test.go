@@ -0,0 +1,80 @@
+package book
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func TestWriteRead(t *testing.T) {
+	b := buildBook(t, 10, 1)
+	var buf bytes.Buffer
+	if err := Write(&buf, b); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || lines[0] != "variant american" {
+		t.Errorf("wrong book written:\n%s", buf.String())
+	}
+
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Variant().Name != c.AmericanVariant.Name || read.Len() != b.Len() {
+		t.Fatalf("wrong book read, variant %v with %d positions", read.Variant(), read.Len())
+	}
+	for _, moves := range [][]string{{}, {"11-15"}, {"11-15", "24-20"}} {
+		assertEntries(t, entriesAfter(t, read, moves...), entriesAfter(t, b, moves...))
+	}
+}
+
+func TestReadFormat(t *testing.T) {
+	s := `
+	# comments and empty lines are ignored
+	variant brazilian
+
+	00000000000000ff 22-18 3 21-17 1
+	0000000000000100 9x18 0
+	`
+	b, err := Read(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Variant().Name != c.BrazilianVariant.Name || b.Len() != 2 {
+		t.Fatalf("wrong book read, variant %v with %d positions", b.Variant(), b.Len())
+	}
+	entries := b.positions[0xff]
+	if len(entries) != 2 || entries[0].Move.String() != "22-18" || entries[0].Weight != 3 || entries[1].Weight != 1 {
+		t.Errorf("wrong entries %v", entries)
+	}
+	if entries := b.positions[0x100]; len(entries) != 1 || !entries[0].Move.Capture {
+		t.Errorf("wrong entries %v", entries)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"# only a comment",
+		"00000000000000ff 22-18 3",
+		"variant",
+		"variant polish",
+		"variant brazilian\nxyz 22-18 3",
+		"variant brazilian\n00000000000000ff",
+		"variant brazilian\n00000000000000ff 22-18",
+		"variant brazilian\n00000000000000ff 22 3",
+		"variant brazilian\n00000000000000ff 22-18 x",
+		"variant brazilian\n00000000000000ff 22-18 -1",
+		"variant brazilian\n" + strings.Repeat("1", 1<<17),
+	}
+	for _, test := range tests {
+		if _, err := Read(strings.NewReader(test)); err == nil {
+			t.Errorf("%.40q: expected an error", test)
+		} else if !strings.HasPrefix(err.Error(), "book: ") {
+			t.Errorf("%.40q: wrong error %q", test, err)
+		}
+	}
+}
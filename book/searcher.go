@@ -0,0 +1,34 @@
+package book
+
+import (
+	"context"
+	"math/rand"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+)
+
+// A Searcher plays the moves of the book while the game is in it,
+// and searches with the Fallback searcher once it's out of the book.
+type Searcher struct {
+	Book     *Book
+	Fallback minimax.Searcher
+	// Picks among the plies of the book by their weights, as in Book.Probe.
+	// A Searcher given one shouldn't play on several goroutines at once.
+	Rand *rand.Rand
+}
+
+var _ minimax.Searcher = Searcher{}
+
+func (s Searcher) Search(g *c.Game) c.Ply {
+	return s.SearchContext(context.Background(), g)
+}
+
+func (s Searcher) SearchContext(ctx context.Context, g *c.Game) c.Ply {
+	if s.Book != nil {
+		if p, ok := s.Book.Probe(g, s.Rand); ok {
+			return p
+		}
+	}
+	return s.Fallback.SearchContext(ctx, g)
+}
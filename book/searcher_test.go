@@ -0,0 +1,42 @@
+package book
+
+import (
+	"math/rand"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+)
+
+func TestSearcher(t *testing.T) {
+	g := c.NewGame()
+	b := New(c.BrazilianVariant)
+	bookPly := g.Plies()[3]
+	b.Add(g, bookPly, 1)
+
+	s := Searcher{
+		Book:     b,
+		Fallback: minimax.DepthLimitedSearcher{ToMax: c.BlackColor, Heuristic: minimax.WeightedCountHeuristic, DepthLimit: 2},
+		Rand:     rand.New(rand.NewSource(1)),
+	}
+	if p := s.Search(g); !p.Equals(bookPly) {
+		t.Errorf("expected the ply of the book %v, got %v", bookPly, p)
+	}
+
+	// out of the book
+	g.DoPly(bookPly)
+	assertLegal(t, g, s.Search(g))
+
+	s.Book = nil
+	assertLegal(t, g, s.Search(g))
+}
+
+func assertLegal(t *testing.T, g *c.Game, ply c.Ply) {
+	t.Helper()
+	for _, p := range g.Plies() {
+		if p.Equals(ply) {
+			return
+		}
+	}
+	t.Errorf("searcher returned a ply that is not legal: %v", ply)
+}
@@ -0,0 +1,91 @@
+// Command buildbook makes an opening book out of PDN game collections,
+// to be loaded with book.Read.
+//
+//	buildbook -variant american -plies 12 -o american.book games1.pdn games2.pdn
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/luc527/go_checkers/book"
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+func main() {
+	variantName := flag.String("variant", c.BrazilianVariant.Name, "variant of the book, games of other variants are skipped")
+	plies := flag.Int("plies", 16, "number of plies of each game added to the book")
+	minGames := flag.Int("min", 2, "minimum number of games a move must have been played in")
+	out := flag.String("o", "", "output file (default <variant>.book)")
+	verbose := flag.Bool("v", false, "print why each game skipped was skipped")
+	flag.Parse()
+
+	if err := run(*variantName, *plies, *minGames, *out, *verbose, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(variantName string, plies, minGames int, out string, verbose bool, files []string) error {
+	variant, ok := c.VariantFromName(variantName)
+	if !ok {
+		return fmt.Errorf("unknown variant %q", variantName)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no PDN files given")
+	}
+	if out == "" {
+		out = variant.Name + ".book"
+	}
+
+	bd := book.NewBuilder(variant, plies)
+	added, skipped := 0, 0
+	for _, name := range files {
+		games, err := readFile(name)
+		if err != nil {
+			return err
+		}
+		for i, g := range games {
+			if err := bd.AddGame(g); err != nil {
+				skipped++
+				if verbose {
+					fmt.Fprintf(os.Stderr, "%s: game %d: %v\n", name, i+1, err)
+				}
+				continue
+			}
+			added++
+		}
+	}
+
+	b := bd.Book(minGames)
+	fmt.Printf("%d games added, %d skipped, %d positions in the book\n", added, skipped, b.Len())
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := book.Write(f, b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("written to %s\n", out)
+	return nil
+}
+
+func readFile(name string) ([]*pdn.Game, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	games, err := pdn.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return games, nil
+}
@@ -0,0 +1,336 @@
+// Command checkers plays a game of checkers in the terminal, each side played by
+// a person or by a searcher given as a player spec (see tournament.ParsePlayer).
+// Moves are typed in numbered square notation (11-15, 22x15) or as row,col
+// coordinates (5,2-4,3); type help for the other commands.
+//
+//	checkers -variant american -white human -black name=bot,depth=8
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+	"github.com/luc527/go_checkers/pdn"
+	"github.com/luc527/go_checkers/tournament"
+)
+
+const human = "human"
+
+const help = `commands:
+  <move>      play a move, e.g. 11-15, 22x15, 9x18x27 or 5,2-4,3
+  plies       list the legal plies, numbered
+  <n>         play the n-th ply of the list
+  undo        take back plies until it's a person's turn again
+  redo        redo the plies undone
+  hint        ask the hint searcher for a ply
+  squares     show the numbers of the squares
+  fen         print the position in FEN
+  pdn         print the game so far in PDN
+  quit        leave (saving the game if -o was given)`
+
+type config struct {
+	variant string
+	white   string
+	black   string
+	fen     string
+	color   bool
+	out     string
+	hint    string
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.variant, "variant", c.BrazilianVariant.Name, "variant of the game")
+	flag.StringVar(&cfg.white, "white", human, "who plays white: human or a player spec, e.g. depth=6")
+	flag.StringVar(&cfg.black, "black", "name=bot,depth=6", "who plays black: human or a player spec")
+	flag.StringVar(&cfg.fen, "fen", "", "starting position in FEN (default the initial position of the variant)")
+	flag.BoolVar(&cfg.color, "color", true, "draw the board with terminal colors")
+	flag.StringVar(&cfg.out, "o", "", "PDN file the game is written to when leaving")
+	flag.StringVar(&cfg.hint, "hint", "depth=8", "player spec of the searcher giving hints")
+	flag.Parse()
+
+	if err := run(cfg, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type side struct {
+	name string
+	// nil for a person
+	searcher minimax.Searcher
+}
+
+func newSide(spec string, color c.Color) (side, error) {
+	if spec == human {
+		return side{name: human}, nil
+	}
+	p, err := tournament.ParsePlayer(spec)
+	if err != nil {
+		return side{}, err
+	}
+	return side{name: p.Name, searcher: p.New(color)}, nil
+}
+
+type session struct {
+	cfg     config
+	start   *c.Game
+	history *c.History
+	sides   [2]side
+	hint    tournament.Player
+	out     io.Writer
+	// plies as last listed by the plies command, so they can be picked by number
+	listed []c.Ply
+}
+
+func run(cfg config, in io.Reader, out io.Writer) error {
+	v, ok := c.VariantFromName(cfg.variant)
+	if !ok {
+		return fmt.Errorf("unknown variant %q", cfg.variant)
+	}
+	start := c.NewCustomGame(v, nil, pdn.FirstPlayer(v))
+	if cfg.fen != "" {
+		var err error
+		if start, err = c.NewGameFromFEN(v, cfg.fen); err != nil {
+			return err
+		}
+	}
+
+	s := &session{cfg: cfg, start: start, history: c.NewHistory(start.Copy()), out: out}
+	for color, spec := range map[c.Color]string{c.WhiteColor: cfg.white, c.BlackColor: cfg.black} {
+		sd, err := newSide(spec, color)
+		if err != nil {
+			return err
+		}
+		s.sides[color] = sd
+	}
+	hint, err := tournament.ParsePlayer(cfg.hint)
+	if err != nil {
+		return err
+	}
+	s.hint = hint
+
+	scanner := bufio.NewScanner(in)
+	for {
+		g := s.history.Game()
+		s.show()
+		if sd := s.sides[g.ToPlay()]; sd.searcher != nil && !g.Result().Over() {
+			s.playBot(sd)
+			continue
+		}
+
+		fmt.Fprintf(out, "%s> ", g.ToPlay())
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "quit" || line == "exit" {
+			break
+		}
+		if err := s.command(line); err != nil {
+			fmt.Fprintln(out, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+func (s *session) show() {
+	g := s.history.Game()
+	fmt.Fprintln(s.out)
+	fmt.Fprint(s.out, renderBoard(g.Board(), s.cfg.color))
+	if res := g.Result(); res.Over() {
+		fmt.Fprintf(s.out, "game over: %v (undo to take plies back, quit to leave)\n", res)
+	} else {
+		fmt.Fprintf(s.out, "%s to play (%s)\n", g.ToPlay(), s.sides[g.ToPlay()].name)
+	}
+}
+
+func (s *session) playBot(sd side) {
+	g := s.history.Game()
+	started := time.Now()
+	p := sd.searcher.Search(g)
+	if p == nil {
+		// shouldn't happen while the game isn't over, but a person can take over
+		fmt.Fprintf(s.out, "%s found no ply, playing as a person\n", sd.name)
+		s.sides[g.ToPlay()] = side{name: human}
+		return
+	}
+	move := moveString(g, p)
+	if err := s.history.DoPly(p); err != nil {
+		fmt.Fprintln(s.out, err)
+		s.sides[g.ToPlay()] = side{name: human}
+		return
+	}
+	fmt.Fprintf(s.out, "%s plays %s (%v)\n", sd.name, move, time.Since(started).Round(time.Millisecond))
+}
+
+func (s *session) command(line string) error {
+	g := s.history.Game()
+	switch line {
+	case "":
+		return nil
+	case "help", "?":
+		fmt.Fprintln(s.out, help)
+		return nil
+	case "plies", "moves":
+		s.listed = c.CopyPlies(g.Plies())
+		for i, p := range s.listed {
+			fmt.Fprintf(s.out, "%3d. %-12s %v\n", i+1, moveString(g, p), p)
+		}
+		return nil
+	case "undo":
+		return s.undo()
+	case "redo":
+		if err := s.history.Redo(); err != nil {
+			return err
+		}
+		s.listed = nil
+		return nil
+	case "hint":
+		if g.Result().Over() {
+			return fmt.Errorf("the game is over")
+		}
+		p := s.hint.New(g.ToPlay()).Search(g)
+		fmt.Fprintf(s.out, "%s suggests %s\n", s.hint.Name, moveString(g, p))
+		return nil
+	case "squares":
+		fmt.Fprint(s.out, renderSquares(g.Board().Size()))
+		return nil
+	case "fen":
+		fmt.Fprintln(s.out, g.FEN())
+		return nil
+	case "pdn":
+		pg, err := s.pdn()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(s.out, pg)
+		return nil
+	}
+
+	if g.Result().Over() {
+		return fmt.Errorf("the game is over")
+	}
+	var p c.Ply
+	if n, err := strconv.Atoi(line); err == nil {
+		if n < 1 || n > len(s.listed) {
+			return fmt.Errorf("no ply %d, list them with plies", n)
+		}
+		p = s.listed[n-1]
+	} else if p, err = parsePly(g, line); err != nil {
+		return err
+	}
+	s.listed = nil
+	return s.history.DoPly(p)
+}
+
+// Undoes at least one ply, then keeps going until it's a person's turn
+func (s *session) undo() error {
+	if err := s.history.Undo(); err != nil {
+		return err
+	}
+	for s.sides[s.history.Game().ToPlay()].searcher != nil && s.history.CanUndo() {
+		s.history.Undo()
+	}
+	s.listed = nil
+	return nil
+}
+
+func (s *session) pdn() (*pdn.Game, error) {
+	pg, err := pdn.FromPlies(s.start, s.history.Plies())
+	if err != nil {
+		return nil, err
+	}
+	pg.SetTag("White", s.sides[c.WhiteColor].name)
+	pg.SetTag("Black", s.sides[c.BlackColor].name)
+	pg.SetTag("Date", time.Now().Format("2006.01.02"))
+	return pg, nil
+}
+
+func (s *session) save() error {
+	if s.cfg.out == "" {
+		return nil
+	}
+	pg, err := s.pdn()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(s.cfg.out)
+	if err != nil {
+		return err
+	}
+	if err := pdn.Write(f, []*pdn.Game{pg}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func moveString(g *c.Game, p c.Ply) string {
+	m, err := pdn.MoveFromPly(g, p)
+	if err != nil {
+		return p.String()
+	}
+	return m.String()
+}
+
+// Parses a move in numbered square notation, or as a sequence of row,col coordinates.
+// Whether it's a capture can be left out: 22-15 is the same as 22x15 if it's the only ply matching it.
+func parsePly(g *c.Game, s string) (c.Ply, error) {
+	var m pdn.Move
+	if strings.Contains(s, ",") {
+		fields := strings.FieldsFunc(s, func(r rune) bool {
+			return r == '-' || r == 'x' || r == ':' || unicode.IsSpace(r)
+		})
+		for _, field := range fields {
+			n, err := coordSquare(g.Board().Size(), strings.Trim(field, "()"))
+			if err != nil {
+				return nil, err
+			}
+			m.Squares = append(m.Squares, n)
+		}
+		m.Capture = strings.ContainsAny(s, "x:")
+	} else {
+		var err error
+		if m, err = pdn.ParseMove(s); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := pdn.PlyFromMove(g, m)
+	if err == nil {
+		return p, nil
+	}
+	other := pdn.Move{Squares: m.Squares, Capture: !m.Capture}
+	if q, err := pdn.PlyFromMove(g, other); err == nil {
+		return q, nil
+	}
+	return nil, err
+}
+
+func coordSquare(size byte, s string) (int, error) {
+	rs, cs, ok := strings.Cut(s, ",")
+	row, err1 := strconv.Atoi(strings.TrimSpace(rs))
+	col, err2 := strconv.Atoi(strings.TrimSpace(cs))
+	if !ok || err1 != nil || err2 != nil || row < 0 || col < 0 || row >= int(size) || col >= int(size) {
+		return 0, fmt.Errorf("invalid coordinates %q, expected row,col", s)
+	}
+	n, ok := c.SquareNumber(size, byte(row), byte(col))
+	if !ok {
+		return 0, fmt.Errorf("%d,%d is not a playable square", row, col)
+	}
+	return n, nil
+}
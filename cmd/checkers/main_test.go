@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+func testConfig() config {
+	return config{
+		variant: c.AmericanVariant.Name,
+		white:   human,
+		black:   human,
+		hint:    "depth=2",
+	}
+}
+
+// Runs the session with the lines as input, returning what it wrote
+func play(t *testing.T, cfg config, lines ...string) string {
+	t.Helper()
+	var out strings.Builder
+	if err := run(cfg, strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func coords(size byte, n int) string {
+	row, col, _ := c.SquareCoord(size, n)
+	return fmt.Sprintf("%d,%d", row, col)
+}
+
+func readGame(t *testing.T, file string) *pdn.Game {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	games, err := pdn.Read(f)
+	if err != nil || len(games) != 1 {
+		t.Fatalf("expected one game in %s, got %d, %v", file, len(games), err)
+	}
+	return games[0]
+}
+
+func TestRunMoves(t *testing.T) {
+	cfg := testConfig()
+	cfg.out = filepath.Join(t.TempDir(), "game.pdn")
+
+	// american: black moves first
+	g := c.NewCustomGame(c.AmericanVariant, nil, c.BlackColor)
+	g.DoPly(mustPly(t, g, "11-15"))
+	g.DoPly(mustPly(t, g, "22-18"))
+	// black's reply is forced, a capture
+	listed := moveString(g, g.Plies()[0])
+	g.DoPly(g.Plies()[0])
+
+	out := play(t, cfg,
+		"11-15",
+		coords(8, 22)+"-"+coords(8, 18),
+		"plies",
+		"1",
+		"help",
+		"",
+		"squares",
+		"fen",
+		"pdn",
+		"hint",
+		"quit",
+	)
+
+	pg := readGame(t, cfg.out)
+	var moves []string
+	for _, m := range pg.Moves {
+		moves = append(moves, m.String())
+	}
+	if want := []string{"11-15", "22-18", listed}; strings.Join(moves, " ") != strings.Join(want, " ") {
+		t.Errorf("expected the moves %v, got %v", want, moves)
+	}
+	if white, _ := pg.Tag("White"); white != human {
+		t.Errorf("expected white to be %q, got %q", human, white)
+	}
+	for _, want := range []string{"commands:", "     1     2     3     4", g.FEN(), "1. 11-15 22-18", "suggests"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected the output to contain %q", want)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, tc := range []struct {
+		line, want string
+	}{
+		{"9,9-8,8", `invalid coordinates "9,9", expected row,col`},
+		{"1,a-2,1", `invalid coordinates "1,a", expected row,col`},
+		{"5,2-4", `invalid coordinates "4", expected row,col`},
+		{"0,0-1,1", "0,0 is not a playable square"},
+		{"5", "no ply 5, list them with plies"},
+		{"redo", "history: nothing to redo"},
+		{"undo", "history: nothing to undo"},
+		{"11-16x", `pdn: invalid square "11-16" in move "11-16x"`},
+		{"11-17", "illegal move 11-17"},
+		{"22-18", "illegal move 22-18"},
+	} {
+		out := play(t, testConfig(), tc.line, "quit")
+		if !strings.Contains(out, "> "+tc.want) {
+			t.Errorf("%q: expected the error %q, got %q", tc.line, tc.want, out)
+		}
+		if strings.Count(out, "black to play") != 2 {
+			t.Errorf("%q: expected no ply to be played", tc.line)
+		}
+	}
+
+	// the numbers listed are forgotten once a ply is played
+	out := play(t, testConfig(), "plies", "11-15", "1", "quit")
+	if !strings.Contains(out, "no ply 1, list them with plies") {
+		t.Errorf("expected the listed plies to be forgotten, got %q", out)
+	}
+
+	for _, cfg := range []config{
+		{variant: "chess"},
+		{variant: c.AmericanVariant.Name, fen: "nonsense"},
+		{variant: c.AmericanVariant.Name, white: "depth=x"},
+		{variant: c.AmericanVariant.Name, white: human, black: human, hint: "depth=x"},
+	} {
+		if err := run(cfg, strings.NewReader(""), new(strings.Builder)); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+}
+
+func TestRunUndoRedo(t *testing.T) {
+	cfg := testConfig()
+	cfg.out = filepath.Join(t.TempDir(), "game.pdn")
+	play(t, cfg, "11-15", "22-18", "undo", "undo", "redo", "quit")
+	if pg := readGame(t, cfg.out); len(pg.Moves) != 1 {
+		t.Errorf("expected one move after undoing two and redoing one, got %v", pg.Moves)
+	}
+
+	// against a bot, undo takes back the bot's ply too
+	cfg.white = "name=bot,depth=1"
+	out := play(t, cfg, "11-15", "undo", "quit")
+	if !strings.Contains(out, "bot plays") {
+		t.Errorf("expected the bot to play, got %q", out)
+	}
+	if pg := readGame(t, cfg.out); len(pg.Moves) != 0 {
+		t.Errorf("expected undo to go back to black's first turn, got %v", pg.Moves)
+	}
+}
+
+func TestRunBots(t *testing.T) {
+	cfg := testConfig()
+	cfg.white = "name=w,depth=1"
+	cfg.black = "name=b,depth=1"
+	cfg.out = filepath.Join(t.TempDir(), "game.pdn")
+
+	// the bots play until the game is over, and undoing goes all the way back,
+	// since no person plays either side, so they play the game once more
+	// (shown over after the bots' game, after the move refused and after the replay)
+	out := play(t, cfg, "11-15", "undo", "quit")
+	if n := strings.Count(out, "game over"); n != 3 {
+		t.Errorf("expected the game to be over three times, got %d", n)
+	}
+	if n := strings.Count(out, "w plays"); n < 2 {
+		t.Errorf("expected the game to be played twice, got %d plies by white", n)
+	}
+	if !strings.Contains(out, "the game is over") {
+		t.Errorf("expected an error for a move after the game is over")
+	}
+	pg := readGame(t, cfg.out)
+	if pg.Result == pdn.UnknownResult {
+		t.Errorf("expected the game to be over, got %q", pg.Result)
+	}
+	if white, _ := pg.Tag("White"); white != "w" {
+		t.Errorf("expected white to be w, got %q", white)
+	}
+}
+
+func TestRunFEN(t *testing.T) {
+	cfg := testConfig()
+	cfg.fen = "W:W26:B14,15,22,23"
+	cfg.color = true
+	out := play(t, cfg, "fen", "hint", "quit")
+	if !strings.Contains(out, cfg.fen) || !strings.Contains(out, darkTile) {
+		t.Errorf("expected the colored position of the FEN, got %q", out)
+	}
+}
+
+func mustPly(t *testing.T, g *c.Game, s string) c.Ply {
+	t.Helper()
+	p, err := parsePly(g, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParsePlyCaptureFlag(t *testing.T) {
+	b, toPlay, err := c.ParseFEN(8, "W:W26:B14,15,22,23")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := c.NewCustomGame(c.AmericanVariant, b, toPlay)
+	for _, p := range g.Plies() {
+		move := moveString(g, p)
+		if !strings.Contains(move, "x") {
+			t.Fatalf("expected only captures, got %s", move)
+		}
+		// the capture flag can be left out, with numbers or coordinates
+		squares := strings.Split(move, "x")
+		var cs []string
+		for _, sq := range squares {
+			var n int
+			fmt.Sscan(sq, &n)
+			cs = append(cs, coords(8, n))
+		}
+		for _, s := range []string{move, strings.ReplaceAll(move, "x", "-"), strings.Join(cs, "-"), strings.Join(cs, "x")} {
+			if q, err := parsePly(g, s); err != nil || !q.Equals(p) {
+				t.Errorf("%s: expected %v, got %v, %v", s, p, q, err)
+			}
+		}
+	}
+	if _, err := parsePly(g, "26-30"); err == nil {
+		t.Errorf("expected an illegal move to fail either way")
+	}
+	if got := moveString(g, c.Ply{c.MakeMoveInstruction(0, 0, 1, 1)}); got == "" {
+		t.Errorf("expected the ply itself for one that isn't a move")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// ANSI escape codes
+const (
+	reset      = "\x1b[0m"
+	darkTile   = "\x1b[48;5;94m"
+	lightTile  = "\x1b[48;5;180m"
+	whitePiece = "\x1b[1;97m"
+	blackPiece = "\x1b[1;30m"
+	faint      = "\x1b[2;37m"
+)
+
+// Same symbols as Board.String
+func pieceSymbol(color c.Color, kind c.Kind) string {
+	switch {
+	case color == c.WhiteColor && kind == c.KingKind:
+		return "@"
+	case color == c.WhiteColor:
+		return "o"
+	case kind == c.KingKind:
+		return "#"
+	default:
+		return "x"
+	}
+}
+
+// Board.String, or with colors a board with the rows and columns around it
+// and the numbers of the empty playable squares
+func renderBoard(b *c.Board, color bool) string {
+	if !color {
+		return b.String()
+	}
+	size := b.Size()
+	var sb strings.Builder
+	header := "   "
+	for col := byte(0); col < size; col++ {
+		header += fmt.Sprintf(" %d ", col)
+	}
+	sb.WriteString(header + "\n")
+	for row := byte(0); row < size; row++ {
+		fmt.Fprintf(&sb, " %d ", row)
+		for col := byte(0); col < size; col++ {
+			if c.TileColor(row, col) == c.WhiteColor {
+				sb.WriteString(lightTile + "   " + reset)
+				continue
+			}
+			sb.WriteString(darkTile)
+			if b.IsOccupied(row, col) {
+				pc, kind := b.Get(row, col)
+				fg := blackPiece
+				if pc == c.WhiteColor {
+					fg = whitePiece
+				}
+				sb.WriteString(fg + " " + pieceSymbol(pc, kind) + " ")
+			} else {
+				n, _ := c.SquareNumber(size, row, col)
+				fmt.Fprintf(&sb, "%s%3d", faint, n)
+			}
+			sb.WriteString(reset)
+		}
+		fmt.Fprintf(&sb, " %d\n", row)
+	}
+	sb.WriteString(header + "\n")
+	return sb.String()
+}
+
+// The numbers of the playable squares, laid out as on the board
+func renderSquares(size byte) string {
+	var sb strings.Builder
+	for row := byte(0); row < size; row++ {
+		for col := byte(0); col < size; col++ {
+			if n, ok := c.SquareNumber(size, row, col); ok {
+				fmt.Fprintf(&sb, "%3d", n)
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+// Command server serves games of checkers over HTTP (see package server).
+//
+//	server -addr :8080 -maxgames 1000 -origins https://example.com
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/luc527/go_checkers/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	maxGames := flag.Int("maxgames", server.DefaultMaxGames, "games kept at the same time, 0 for no limit")
+	origins := flag.String("origins", "", "comma separated origins of other sites whose pages may join rooms")
+	flag.Parse()
+
+	s := server.New()
+	s.MaxGames = *maxGames
+	if *origins != "" {
+		s.AllowedOrigins = strings.Split(*origins, ",")
+	}
+	hs := &http.Server{
+		Addr:              *addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("listening on %s", *addr)
+	if err := hs.ListenAndServe(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,56 @@
+// Command tablebase generates an endgame tablebase and writes it to a file,
+// to be loaded with tablebase.Read.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/tablebase"
+)
+
+func main() {
+	variantName := flag.String("variant", c.BrazilianVariant.Name, "variant of the tablebase")
+	pieces := flag.Int("pieces", 3, fmt.Sprintf("maximum number of pieces on the board (2 to %d)", tablebase.MaxPieces))
+	out := flag.String("o", "", "output file (default <variant><pieces>.tb)")
+	flag.Parse()
+
+	if err := run(*variantName, *pieces, *out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(variantName string, pieces int, out string) error {
+	variant, ok := c.VariantFromName(variantName)
+	if !ok {
+		return fmt.Errorf("unknown variant %q", variantName)
+	}
+	if out == "" {
+		out = fmt.Sprintf("%s%d.tb", variant.Name, pieces)
+	}
+
+	start := time.Now()
+	tb, err := tablebase.Generate(variant, pieces)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("solved %d positions in %v\n", tb.Positions(), time.Since(start).Round(time.Millisecond))
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := tablebase.Write(f, tb); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("written to %s\n", out)
+	return nil
+}
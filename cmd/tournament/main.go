@@ -0,0 +1,213 @@
+// Command tournament plays matches between searchers, each given as a player spec
+// (see tournament.ParsePlayer), and reports their Elo rating differences.
+// The games are written to a PDN file. Interrupting it reports the games played so far.
+//
+//	tournament -games 200 -gauntlet -sprt 0,10 -weights tuned=tuned.json \
+//		name=new,depth=6,heuristic=tuned name=old,depth=6,heuristic=Positional
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+	"github.com/luc527/go_checkers/pdn"
+	"github.com/luc527/go_checkers/tournament"
+)
+
+type config struct {
+	variant  string
+	games    int
+	gauntlet bool
+	openings int
+	plies    int
+	book     string
+	maxPlies int
+	out      string
+	sprt     string
+	alpha    float64
+	beta     float64
+	workers  int
+	seed     int64
+	weights  weightsFlag
+}
+
+// name=file pairs, each registering the weights in the file as a heuristic with the name
+type weightsFlag []string
+
+func (w *weightsFlag) String() string {
+	return strings.Join(*w, " ")
+}
+
+func (w *weightsFlag) Set(s string) error {
+	*w = append(*w, s)
+	return nil
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.variant, "variant", c.BrazilianVariant.Name, "variant of the games")
+	flag.IntVar(&cfg.games, "games", 20, "games per pairing, rounded up to an even number")
+	flag.BoolVar(&cfg.gauntlet, "gauntlet", false, "the first player plays against each other one, instead of everyone against everyone")
+	flag.IntVar(&cfg.openings, "openings", 0, "number of random openings (default half the games)")
+	flag.IntVar(&cfg.plies, "plies", 4, "plies of each opening")
+	flag.StringVar(&cfg.book, "book", "", "PDN file to take the openings from, instead of random ones")
+	flag.IntVar(&cfg.maxPlies, "maxplies", tournament.DefaultMaxPlies, "plies after which a game is adjudicated as a draw")
+	flag.StringVar(&cfg.out, "o", "tournament.pdn", "output PDN file")
+	flag.StringVar(&cfg.sprt, "sprt", "", "elo0,elo1 of a SPRT for each pairing, e.g. 0,10")
+	flag.Float64Var(&cfg.alpha, "alpha", 0.05, "SPRT chance of accepting H1 when H0 is true")
+	flag.Float64Var(&cfg.beta, "beta", 0.05, "SPRT chance of accepting H0 when H1 is true")
+	flag.IntVar(&cfg.workers, "workers", 1, "games played at the same time")
+	flag.Int64Var(&cfg.seed, "seed", 0, "seed of the random openings (default the time)")
+	flag.Var(&cfg.weights, "weights", "name=file, registers the weights in the JSON file as a heuristic (repeatable)")
+	flag.Parse()
+
+	if err := run(cfg, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg config, specs []string) error {
+	v, ok := c.VariantFromName(cfg.variant)
+	if !ok {
+		return fmt.Errorf("unknown variant %q", cfg.variant)
+	}
+	for _, w := range cfg.weights {
+		if err := registerWeights(w); err != nil {
+			return err
+		}
+	}
+	if len(specs) < 2 {
+		return fmt.Errorf("at least two players are needed")
+	}
+	var players []tournament.Player
+	for _, spec := range specs {
+		p, err := tournament.ParsePlayer(spec)
+		if err != nil {
+			return err
+		}
+		players = append(players, p)
+	}
+
+	t := &tournament.Tournament{
+		Variant:         v,
+		Players:         players,
+		Pairings:        tournament.RoundRobin(len(players)),
+		GamesPerPairing: cfg.games,
+		MaxPlies:        cfg.maxPlies,
+		Workers:         cfg.workers,
+		OnGame: func(g tournament.Game) {
+			fmt.Printf("round %d: %s vs %s: %v %s\n", g.Round+1, g.White, g.Black, g.Result, g.Termination)
+		},
+	}
+	if cfg.gauntlet {
+		t.Pairings = tournament.Gauntlet(len(players))
+	}
+	if cfg.sprt != "" {
+		test, err := parseSPRT(cfg.sprt, cfg.alpha, cfg.beta)
+		if err != nil {
+			return err
+		}
+		t.SPRT = &test
+	}
+	openings, err := readOpenings(cfg, v)
+	if err != nil {
+		return err
+	}
+	t.Openings = openings
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	res, err := t.Run(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	fmt.Println()
+	if err := res.Report(os.Stdout, t.SPRT); err != nil {
+		return err
+	}
+	games, err := res.PDN()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(cfg.out)
+	if err != nil {
+		return err
+	}
+	if err := pdn.Write(f, games); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func registerWeights(s string) error {
+	name, file, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("invalid weights %q, expected name=file", s)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := minimax.ReadWeights(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	minimax.RegisterHeuristic(name, w.Heuristic())
+	return nil
+}
+
+func parseSPRT(s string, alpha, beta float64) (tournament.SPRT, error) {
+	s0, s1, ok := strings.Cut(s, ",")
+	elo0, err0 := strconv.ParseFloat(s0, 64)
+	elo1, err1 := strconv.ParseFloat(s1, 64)
+	if !ok || err0 != nil || err1 != nil || elo0 >= elo1 {
+		return tournament.SPRT{}, fmt.Errorf("invalid SPRT bounds %q, expected elo0,elo1 with elo0 < elo1", s)
+	}
+	return tournament.SPRT{Elo0: elo0, Elo1: elo1, Alpha: alpha, Beta: beta}, nil
+}
+
+func readOpenings(cfg config, v c.Variant) ([][]c.Ply, error) {
+	if cfg.book != "" {
+		f, err := os.Open(cfg.book)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		games, err := pdn.Read(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.book, err)
+		}
+		openings, err := tournament.OpeningsFromPDN(games, v, cfg.plies)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.book, err)
+		}
+		if len(openings) == 0 {
+			return nil, fmt.Errorf("%s: no games with %d plies from the initial position", cfg.book, cfg.plies)
+		}
+		return openings, nil
+	}
+
+	n := cfg.openings
+	if n <= 0 {
+		n = (cfg.games + 1) / 2
+	}
+	seed := cfg.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return tournament.RandomOpenings(v, n, cfg.plies, rand.New(rand.NewSource(seed))), nil
+}
@@ -0,0 +1,143 @@
+// Command tune tunes the weights of the positional heuristic on the positions of
+// games from PDN files and/or played by the engine against itself, and writes
+// them to a file to be loaded with minimax.ReadWeights.
+//
+//	tune -selfplay 200 -o weights.json games.pdn
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+	"github.com/luc527/go_checkers/pdn"
+	"github.com/luc527/go_checkers/tuning"
+)
+
+type config struct {
+	weights    string
+	out        string
+	variant    string
+	skip       int
+	selfPlay   int
+	depth      int
+	fixed      string
+	iterations int
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.weights, "weights", "", "JSON file with the initial weights (default minimax.DefaultWeights)")
+	flag.StringVar(&cfg.out, "o", "weights.json", "output file")
+	flag.StringVar(&cfg.variant, "variant", c.BrazilianVariant.Name, "variant of the self-play games")
+	flag.IntVar(&cfg.skip, "skip", 8, "plies skipped at the start of each game")
+	flag.IntVar(&cfg.selfPlay, "selfplay", 0, "number of self-play games")
+	flag.IntVar(&cfg.depth, "depth", 3, "search depth in self-play games")
+	flag.StringVar(&cfg.fixed, "fixed", "pawn", "comma separated weights not tuned")
+	flag.IntVar(&cfg.iterations, "iterations", 0, "maximum passes over the weights, 0 for no limit")
+	flag.Parse()
+
+	if err := run(cfg, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg config, files []string) error {
+	initial := minimax.DefaultWeights
+	if cfg.weights != "" {
+		f, err := os.Open(cfg.weights)
+		if err != nil {
+			return err
+		}
+		initial, err = minimax.ReadWeights(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	var positions []tuning.Position
+	for _, name := range files {
+		ps, err := readPositions(name, cfg.skip)
+		if err != nil {
+			return err
+		}
+		positions = append(positions, ps...)
+	}
+
+	if cfg.selfPlay > 0 {
+		v, ok := c.VariantFromName(cfg.variant)
+		if !ok {
+			return fmt.Errorf("unknown variant %q", cfg.variant)
+		}
+		searcher := func(color c.Color) minimax.Searcher {
+			return minimax.DepthLimitedSearcher{
+				ToMax:      color,
+				Heuristic:  initial.Heuristic(),
+				DepthLimit: cfg.depth,
+				Randomize:  true,
+			}
+		}
+		for i := 0; i < cfg.selfPlay; i++ {
+			start, plies, result := tuning.SelfPlay(v, searcher(c.WhiteColor), searcher(c.BlackColor), 300)
+			ps, err := tuning.PositionsFromGame(start, plies, result, cfg.skip)
+			if err != nil {
+				return err
+			}
+			positions = append(positions, ps...)
+		}
+	}
+	fmt.Printf("%d positions\n", len(positions))
+
+	var fixed []string
+	if cfg.fixed != "" {
+		fixed = strings.Split(cfg.fixed, ",")
+	}
+	res, err := tuning.Tune(initial, positions, tuning.Options{
+		Fixed:         fixed,
+		MaxIterations: cfg.iterations,
+		Progress: func(i int, _ minimax.Weights, err float64) {
+			fmt.Printf("iteration %d: error %.6f\n", i, err)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("error %.6f -> %.6f (scale %.4f)\n", res.InitialError, res.Error, res.Scale)
+
+	f, err := os.Create(cfg.out)
+	if err != nil {
+		return err
+	}
+	if err := minimax.WriteWeights(f, res.Weights); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readPositions(name string, skip int) ([]tuning.Position, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	games, err := pdn.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	var positions []tuning.Position
+	for i, g := range games {
+		ps, err := tuning.PositionsFromPDN(g, skip)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: game %d skipped: %v\n", name, i+1, err)
+			continue
+		}
+		positions = append(positions, ps...)
+	}
+	return positions, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"math/bits"
+	"strconv"
 	"strings"
 )
 
@@ -27,9 +28,16 @@ type piece struct {
 	Kind
 }
 
-var crowningRow = [2]byte{
-	int(BlackColor): 7,
-	int(WhiteColor): 0,
+const (
+	DefaultBoardSize = 8
+	MaxBoardSize     = 10
+)
+
+func crowningRow(c Color, size byte) byte {
+	if c == BlackColor {
+		return size - 1
+	}
+	return 0
 }
 
 var forward = [2]int8{
@@ -82,10 +90,62 @@ func (k Kind) String() string {
 	}
 }
 
+// A bitboard has one bit for each tile (not only the playable ones),
+// wide enough for the biggest board we support (10x10)
+type bitboard [2]uint64
+
+func (x *bitboard) set(n uint) {
+	x[n>>6] |= 1 << (n & 63)
+}
+
+func (x *bitboard) unset(n uint) {
+	x[n>>6] &^= 1 << (n & 63)
+}
+
+func (x *bitboard) get(n uint) byte {
+	return byte((x[n>>6] >> (n & 63)) & 1)
+}
+
+func onesCount(x bitboard) int {
+	return bits.OnesCount64(x[0]) + bits.OnesCount64(x[1])
+}
+
+func andNot(x, y bitboard) bitboard {
+	return bitboard{x[0] &^ y[0], x[1] &^ y[1]}
+}
+
+func and(x, y bitboard) bitboard {
+	return bitboard{x[0] & y[0], x[1] & y[1]}
+}
+
 type Board struct {
-	occupied uint64
-	white    uint64
-	king     uint64
+	occupied bitboard
+	white    bitboard
+	king     bitboard
+	// zero means DefaultBoardSize, so that new(Board) is an empty 8x8 board
+	size byte
+	// zobrist hash of the pieces on the board, kept up to date by the methods that change it
+	hash uint64
+}
+
+func validBoardSize(size byte) bool {
+	return size >= 4 && size <= MaxBoardSize && size%2 == 0
+}
+
+// Creates an empty board with size rows and size columns.
+// Panics if the size isn't even or is bigger than MaxBoardSize.
+func NewBoard(size byte) *Board {
+	if !validBoardSize(size) {
+		panic(fmt.Sprintf("invalid board size %d", size))
+	}
+	return &Board{size: size}
+}
+
+func (b *Board) Size() byte {
+	if b.size == 0 {
+		return DefaultBoardSize
+	}
+	return b.size
 }
 
 func pieceToRune(c Color, k Kind) rune {
@@ -105,18 +165,19 @@ func pieceToRune(c Color, k Kind) rune {
 
 func (b *Board) String() string {
 	buf := new(bytes.Buffer)
+	size := b.Size()
 
 	buf.WriteRune(' ')
-	for col := byte(0); col < 8; col++ {
+	for col := byte(0); col < size; col++ {
 		buf.WriteRune('0' + rune(col))
 	}
 	buf.WriteRune(' ')
 	// for alignment when writing side by side
 
-	for row := byte(0); row < 8; row++ {
+	for row := byte(0); row < size; row++ {
 		buf.WriteString("\n")
 		buf.WriteRune('0' + rune(row))
-		for col := byte(0); col < 8; col++ {
+		for col := byte(0); col < size; col++ {
 			if b.IsOccupied(row, col) {
 				buf.WriteRune(pieceToRune(b.Get(row, col)))
 			} else if TileColor(row, col) == BlackColor {
@@ -130,7 +191,7 @@ func (b *Board) String() string {
 
 	buf.WriteRune('\n')
 	buf.WriteRune(' ')
-	for col := byte(0); col < 8; col++ {
+	for col := byte(0); col < size; col++ {
 		buf.WriteRune('0' + rune(col))
 	}
 	buf.WriteRune(' ')
@@ -147,16 +208,19 @@ func TileColor(row, col byte) Color {
 	}
 }
 
+// Leaves the two middle rows empty and fills the rest,
+// 3 rows for each player in a 8x8 board, 4 rows in a 10x10 board
 func PlaceInitialPieces(b *Board) {
-	for row := byte(0); row <= 2; row++ {
-		for col := byte(0); col < 8; col++ {
+	size := b.Size()
+	for row := byte(0); row < size/2-1; row++ {
+		for col := byte(0); col < size; col++ {
 			if TileColor(row, col) == BlackColor {
 				b.Set(row, col, BlackColor, PawnKind)
 			}
 		}
 	}
-	for row := byte(5); row <= 7; row++ {
-		for col := byte(0); col < 8; col++ {
+	for row := size/2 + 1; row < size; row++ {
+		for col := byte(0); col < size; col++ {
 			if TileColor(row, col) == BlackColor {
 				b.Set(row, col, WhiteColor, PawnKind)
 			}
@@ -164,29 +228,42 @@ func PlaceInitialPieces(b *Board) {
 	}
 }
 
-func coordMask(row, col byte) uint64 {
-	return 1 << (uint64(row)*8 + uint64(col))
+func (b *Board) index(row, col byte) uint {
+	return uint(row)*uint(b.Size()) + uint(col)
+}
+
+// zobrist key of the piece at index n, or 0 if there's none
+func (b *Board) pieceKey(n uint) uint64 {
+	if b.occupied.get(n) == 0 {
+		return 0
+	}
+	return zobristPieceKey(n, Color(b.white.get(n)), Kind(b.king.get(n)))
 }
 
 func (b *Board) Clear(row, col byte) {
-	b.occupied &^= coordMask(row, col)
+	n := b.index(row, col)
+	b.hash ^= b.pieceKey(n)
+	b.occupied.unset(n)
 }
 
 func (b *Board) Set(row, col byte, c Color, k Kind) {
-	x := coordMask(row, col)
+	n := b.index(row, col)
 
-	b.occupied |= x
+	b.hash ^= b.pieceKey(n)
+	b.hash ^= zobristPieceKey(n, c, k)
+
+	b.occupied.set(n)
 
 	if c == WhiteColor {
-		b.white |= x
+		b.white.set(n)
 	} else {
-		b.white &^= x
+		b.white.unset(n)
 	}
 
 	if k == KingKind {
-		b.king |= x
+		b.king.set(n)
 	} else {
-		b.king &^= x
+		b.king.unset(n)
 	}
 }
 
@@ -197,25 +274,33 @@ func (b *Board) Move(srow, scol, drow, dcol byte) {
 }
 
 func (b *Board) Crown(row, col byte) {
-	x := coordMask(row, col)
-	b.king |= x
+	n := b.index(row, col)
+	b.hash ^= b.pieceKey(n)
+	b.king.set(n)
+	b.hash ^= b.pieceKey(n)
 }
 
 func (b *Board) Uncrown(row, col byte) {
-	x := uint64(1 << (uint64(row)*8 + uint64(col)))
-	b.king &^= x
+	n := b.index(row, col)
+	b.hash ^= b.pieceKey(n)
+	b.king.unset(n)
+	b.hash ^= b.pieceKey(n)
+}
+
+// Zobrist hash of the pieces on the board.
+// Equal boards have equal hashes; different boards almost always have different ones.
+func (b *Board) Hash() uint64 {
+	return b.hash
 }
 
 func (b *Board) IsOccupied(row, col byte) bool {
-	x := coordMask(row, col)
-	return b.occupied&x != 0
+	return b.occupied.get(b.index(row, col)) != 0
 }
 
 func (b *Board) Get(row, col byte) (c Color, k Kind) {
-	n := uint64(row)*8 + uint64(col)
-	x := uint64(1 << n)
-	k = Kind((b.king & x) >> n)
-	c = Color((b.white & x) >> n)
+	n := b.index(row, col)
+	k = Kind(b.king.get(n))
+	c = Color(b.white.get(n))
 	return
 }
 
@@ -224,6 +309,8 @@ func (b *Board) Copy() *Board {
 	c.occupied = b.occupied
 	c.white = b.white
 	c.king = b.king
+	c.size = b.size
+	c.hash = b.hash
 	return &c
 }
 
@@ -237,17 +324,17 @@ type PieceCount struct {
 func (b *Board) PieceCount() PieceCount {
 	var c PieceCount
 
-	king := b.occupied & b.king
-	pawn := b.occupied &^ b.king
+	king := and(b.occupied, b.king)
+	pawn := andNot(b.occupied, b.king)
 
-	kings := bits.OnesCount64(king)
-	pawns := bits.OnesCount64(pawn)
+	kings := onesCount(king)
+	pawns := onesCount(pawn)
 
-	whitePawns := bits.OnesCount64(pawn & b.white)
+	whitePawns := onesCount(and(pawn, b.white))
 	c.WhitePawns = int8(whitePawns)
 	c.BlackPawns = int8(pawns - whitePawns)
 
-	whiteKings := bits.OnesCount64(king & b.white)
+	whiteKings := onesCount(and(king, b.white))
 	c.WhiteKings = int8(whiteKings)
 	c.BlackKings = int8(kings - whiteKings)
 
@@ -270,11 +357,12 @@ func (b *Board) Equals(o *Board) bool {
 	}
 	// Faster than iterating through the whole board,
 	// and already takes care of mosts cases.
-	if b.PieceCount() != o.PieceCount() {
+	if b.Size() != o.Size() || b.hash != o.hash || b.PieceCount() != o.PieceCount() {
 		return false
 	}
-	for row := byte(0); row < 8; row++ {
-		for col := byte(0); col < 8; col++ {
+	size := b.Size()
+	for row := byte(0); row < size; row++ {
+		for col := byte(0); col < size; col++ {
 			if b.IsOccupied(row, col) != o.IsOccupied(row, col) {
 				return false
 			}
@@ -292,6 +380,11 @@ func (b *Board) Equals(o *Board) bool {
 
 // This is used for testing
 func DecodeBoard(s string) *Board {
+	return DecodeSizedBoard(DefaultBoardSize, s)
+}
+
+// Same as DecodeBoard, but for boards of other sizes
+func DecodeSizedBoard(size byte, s string) *Board {
 	rawLines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
 
 	// trim all liens and filter empty ones
@@ -303,11 +396,11 @@ func DecodeBoard(s string) *Board {
 		}
 	}
 
-	b := new(Board)
+	b := NewBoard(size)
 
 	// parse lines rawLines
-	maxRow := 8
-	if len(lines) < 8 {
+	maxRow := int(size)
+	if len(lines) < maxRow {
 		maxRow = len(lines)
 	}
 
@@ -317,7 +410,7 @@ func DecodeBoard(s string) *Board {
 		// can't count on len(line) because it counts bytes and not unicode runes
 		col := 0
 		for _, cell := range line {
-			if col >= 8 {
+			if col >= int(size) {
 				break
 			}
 
@@ -338,12 +431,21 @@ func DecodeBoard(s string) *Board {
 	return b
 }
 
+// The board is serialized as a list of RCck quadruples (row, column, color, kind),
+// rows and columns being single digits since they go at most to 9.
+// Boards not of the default size are prefixed by their size, e.g. "10:".
 func (b *Board) SerializeInto(buf *bytes.Buffer) error {
 	if b == nil {
 		return nil
 	}
-	for row := byte(0); row < 8; row++ {
-		for c := byte(0); c < 8; c++ {
+	size := b.Size()
+	if size != DefaultBoardSize {
+		if _, err := fmt.Fprintf(buf, "%d:", size); err != nil {
+			return err
+		}
+	}
+	for row := byte(0); row < size; row++ {
+		for c := byte(0); c < size; c++ {
 			if !b.IsOccupied(row, c) {
 				continue
 			}
@@ -402,6 +504,15 @@ func (b Board) MarshalJSON() ([]byte, error) {
 }
 
 func (b *Board) Unserialize(bs []byte) error {
+	if i := bytes.IndexByte(bs, ':'); i >= 0 {
+		size, err := strconv.Atoi(string(bs[:i]))
+		if err != nil || size < 0 || size > MaxBoardSize || !validBoardSize(byte(size)) {
+			return fmt.Errorf("unserialize board: invalid size %q", bs[:i])
+		}
+		b.size = byte(size)
+		bs = bs[i+1:]
+	}
+	size := b.Size()
 	if len(bs)%4 != 0 {
 		return fmt.Errorf("unserialize board: invalid board string (length %d not multiple of 4)", len(bs))
 	}
@@ -410,7 +521,7 @@ func (b *Board) Unserialize(bs []byte) error {
 		row := byte(rowRune) - '0'
 		col := byte(colRune) - '0'
 
-		if row > 7 || col > 7 {
+		if row >= size || col >= size {
 			return fmt.Errorf("unserialize board: invalid position (row %d, col %d)", row, col)
 		}
 
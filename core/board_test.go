@@ -467,3 +467,148 @@ func TestMarshalUnmarshalColor(t *testing.T) {
 		}
 	}
 }
+
+func TestNewBoard(t *testing.T) {
+	b := NewBoard(10)
+	if b.Size() != 10 {
+		t.Errorf("expected size 10, got %d", b.Size())
+	}
+	if new(Board).Size() != DefaultBoardSize {
+		t.Errorf("expected zero board to have the default size")
+	}
+	for _, size := range []byte{0, 3, 12} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected NewBoard(%d) to panic", size)
+				}
+			}()
+			NewBoard(size)
+		}()
+	}
+}
+
+func TestSetOn10x10Board(t *testing.T) {
+	b := NewBoard(10)
+	b.Set(9, 9, WhiteColor, KingKind)
+	b.Set(6, 3, BlackColor, PawnKind)
+	b.Set(0, 9, WhiteColor, PawnKind)
+
+	if c, k := b.Get(9, 9); !b.IsOccupied(9, 9) || c != WhiteColor || k != KingKind {
+		t.Errorf("expected white king at (9, 9)")
+	}
+	if c, k := b.Get(6, 3); !b.IsOccupied(6, 3) || c != BlackColor || k != PawnKind {
+		t.Errorf("expected black pawn at (6, 3)")
+	}
+	b.Uncrown(9, 9)
+	if _, k := b.Get(9, 9); k != PawnKind {
+		t.Errorf("expected (9, 9) to be uncrowned")
+	}
+	b.Clear(0, 9)
+	if b.IsOccupied(0, 9) {
+		t.Errorf("expected (0, 9) to be cleared")
+	}
+	assertPieceCount(t, b.PieceCount(), 1, 0, 1, 0)
+}
+
+func TestInitialPieces10x10(t *testing.T) {
+	b := NewBoard(10)
+	PlaceInitialPieces(b)
+	t.Log("\n" + b.String())
+
+	assertPieceCount(t, b.PieceCount(), 20, 0, 20, 0)
+	for row := byte(0); row < 10; row++ {
+		for col := byte(0); col < 10; col++ {
+			occupied := b.IsOccupied(row, col)
+			if TileColor(row, col) == WhiteColor || row == 4 || row == 5 {
+				if occupied {
+					t.Errorf("expected (%d, %d) to be empty", row, col)
+				}
+				continue
+			}
+			if !occupied {
+				t.Errorf("expected (%d, %d) to be occupied", row, col)
+				continue
+			}
+			color, _ := b.Get(row, col)
+			if (row < 4) != (color == BlackColor) {
+				t.Errorf("wrong color at (%d, %d)", row, col)
+			}
+		}
+	}
+}
+
+func TestBoardEqualsDifferentSizes(t *testing.T) {
+	if new(Board).Equals(NewBoard(10)) {
+		t.Errorf("boards of different sizes shouldn't be equal")
+	}
+	if !new(Board).Equals(NewBoard(8)) {
+		t.Errorf("empty 8x8 boards should be equal")
+	}
+	b := NewBoard(10)
+	b.Set(9, 8, WhiteColor, PawnKind)
+	if !b.Equals(b.Copy()) {
+		t.Errorf("10x10 board should be equal to its copy")
+	}
+}
+
+func TestDecodeSizedBoard(t *testing.T) {
+	b := DecodeSizedBoard(10, `
+		.........x
+		.
+		.
+		.
+		.
+		.
+		.
+		.
+		.
+		@.........o
+	`)
+	want := NewBoard(10)
+	want.Set(0, 9, BlackColor, PawnKind)
+	want.Set(9, 0, WhiteColor, KingKind)
+	assertEqualBoards(t, b, want)
+}
+
+func TestSerializeUnserialize10x10Board(t *testing.T) {
+	b := NewBoard(10)
+	b.Set(9, 8, WhiteColor, KingKind)
+	b.Set(0, 1, BlackColor, PawnKind)
+
+	bs, err := b.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "10:01bp98wk" {
+		t.Errorf("wanted 10:01bp98wk, got %s", bs)
+	}
+
+	var b2 Board
+	if err := b2.Unserialize(bs); err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBoards(t, &b2, b)
+
+	jbs, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b3 Board
+	if err := json.Unmarshal(jbs, &b3); err != nil {
+		t.Fatal(err)
+	}
+	assertEqualBoards(t, &b3, b)
+
+	for _, s := range []string{"12:", "7:", "x:01bp", "-2:", "266:", "10:0:bp"} {
+		var b4 Board
+		if err := b4.Unserialize([]byte(s)); err == nil {
+			t.Errorf("expected error unserializing %q", s)
+		}
+	}
+
+	var b5 Board
+	if err := b5.Unserialize([]byte("98wk")); err == nil {
+		t.Errorf("expected error unserializing out of bounds position in 8x8 board")
+	}
+}
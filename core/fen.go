@@ -0,0 +1,155 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FEN strings describe a position as used in PDN files, e.g. "W:W21,22,K30:B1,2,K5":
+// the side to move, then the pieces of each side (K for kings) by square number.
+// Ranges of squares like "B1-12" are also accepted when parsing, and a trailing "." is ignored.
+
+func parseFENColor(s string) (Color, error) {
+	switch s {
+	case "W":
+		return WhiteColor, nil
+	case "B":
+		return BlackColor, nil
+	default:
+		return 0, fmt.Errorf("fen: invalid color %q", s)
+	}
+}
+
+func parseFENSquare(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("fen: invalid square %q", s)
+	}
+	return n, nil
+}
+
+func ParseFEN(size byte, fen string) (*Board, Color, error) {
+	if !validBoardSize(size) {
+		return nil, 0, fmt.Errorf("fen: invalid board size %d", size)
+	}
+	fen = strings.TrimSuffix(strings.TrimSpace(fen), ".")
+	fields := strings.Split(fen, ":")
+	if len(fields) != 3 {
+		return nil, 0, fmt.Errorf("fen: expected 3 fields separated by ':', got %q", fen)
+	}
+	toPlay, err := parseFENColor(fields[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	b := NewBoard(size)
+	var seen [2]bool
+	for _, field := range fields[1:] {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			return nil, 0, fmt.Errorf("fen: empty field in %q", fen)
+		}
+		color, err := parseFENColor(field[:1])
+		if err != nil {
+			return nil, 0, err
+		}
+		if seen[color] {
+			return nil, 0, fmt.Errorf("fen: the pieces of %v given twice in %q", color, fen)
+		}
+		seen[color] = true
+		for _, piece := range strings.Split(field[1:], ",") {
+			piece = strings.TrimSpace(piece)
+			if piece == "" {
+				continue
+			}
+			kind := PawnKind
+			if strings.HasPrefix(piece, "K") {
+				kind = KingKind
+				piece = piece[1:]
+			}
+			from, to, isRange := strings.Cut(piece, "-")
+			if !isRange {
+				to = from
+			}
+			first, err := parseFENSquare(from)
+			if err != nil {
+				return nil, 0, err
+			}
+			last, err := parseFENSquare(to)
+			if err != nil {
+				return nil, 0, err
+			}
+			if last < first {
+				return nil, 0, fmt.Errorf("fen: invalid range %q", piece)
+			}
+			for n := first; n <= last; n++ {
+				row, col, ok := SquareCoord(size, n)
+				if !ok {
+					return nil, 0, fmt.Errorf("fen: square %d out of the %dx%d board", n, size, size)
+				}
+				if b.IsOccupied(row, col) {
+					return nil, 0, fmt.Errorf("fen: square %d given more than once", n)
+				}
+				if kind == PawnKind && row == crowningRow(color, size) {
+					return nil, 0, fmt.Errorf("fen: %v pawn on square %d should have been crowned", color, n)
+				}
+				b.Set(row, col, color, kind)
+			}
+		}
+	}
+	return b, toPlay, nil
+}
+
+func formatFENColor(c Color) byte {
+	if c == WhiteColor {
+		return 'W'
+	}
+	return 'B'
+}
+
+// FEN of the board with the given player to move
+func (b *Board) FEN(toPlay Color) string {
+	var sb strings.Builder
+	sb.WriteByte(formatFENColor(toPlay))
+	size := b.Size()
+	for _, color := range []Color{WhiteColor, BlackColor} {
+		sb.WriteByte(':')
+		sb.WriteByte(formatFENColor(color))
+		sep := ""
+		for n := 1; n <= SquareCount(size); n++ {
+			row, col, _ := SquareCoord(size, n)
+			if !b.IsOccupied(row, col) {
+				continue
+			}
+			pieceColor, kind := b.Get(row, col)
+			if pieceColor != color {
+				continue
+			}
+			sb.WriteString(sep)
+			if kind == KingKind {
+				sb.WriteByte('K')
+			}
+			sb.WriteString(strconv.Itoa(n))
+			sep = ","
+		}
+	}
+	return sb.String()
+}
+
+func (g *Game) FEN() string {
+	return g.board.FEN(g.toPlay)
+}
+
+// Creates a game of the given variant starting from the position in the FEN string
+func NewGameFromFEN(variant Variant, fen string) (*Game, error) {
+	size := variant.Size
+	if size == 0 {
+		size = DefaultBoardSize
+	}
+	b, toPlay, err := ParseFEN(size, fen)
+	if err != nil {
+		return nil, err
+	}
+	return NewCustomGame(variant, b, toPlay), nil
+}
@@ -0,0 +1,102 @@
+package core
+
+import "testing"
+
+func TestParseFEN(t *testing.T) {
+	b, toPlay, err := ParseFEN(8, "B:W21,22,K30:B1,2,K5.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if toPlay != BlackColor {
+		t.Errorf("expected black to play")
+	}
+	want := NewBoard(8)
+	want.Set(5, 0, WhiteColor, PawnKind)
+	want.Set(5, 2, WhiteColor, PawnKind)
+	want.Set(7, 2, WhiteColor, KingKind)
+	want.Set(0, 1, BlackColor, PawnKind)
+	want.Set(0, 3, BlackColor, PawnKind)
+	want.Set(1, 0, BlackColor, KingKind)
+	if !b.Equals(want) {
+		t.Errorf("wanted\n%vgot\n%v", want, b)
+	}
+}
+
+func TestParseFENRanges(t *testing.T) {
+	b, toPlay, err := ParseFEN(10, "W:B1-20:W31-50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := NewBoard(10)
+	PlaceInitialPieces(initial)
+	if toPlay != WhiteColor || !b.Equals(initial) {
+		t.Errorf("expected the initial 10x10 position, got\n%v", b)
+	}
+}
+
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{
+		"W:W21,22,K30:B1,2,K5",
+		"B:W:BK50",
+		"W:WK1,K2:B",
+	}
+	sizes := []byte{8, 10, 8}
+	for i, fen := range fens {
+		b, toPlay, err := ParseFEN(sizes[i], fen)
+		if err != nil {
+			t.Errorf("%q: %v", fen, err)
+			continue
+		}
+		if got := b.FEN(toPlay); got != fen {
+			t.Errorf("wanted %q got %q", fen, got)
+		}
+	}
+}
+
+func TestParseFENErrors(t *testing.T) {
+	tests := []string{
+		"W:W21",
+		"X:W21:B1",
+		"W:W21:X1",
+		"W:W21::",
+		"W:Wx:B1",
+		"W:W1-y:B2",
+		"W:W33:B1",
+		"W:W0:B1",
+		"W:W-3:B1",
+		"W:W21,21:B1",
+		"W:W21:B21",
+		"W:W2:B1",     // white pawn on the crowning row
+		"W:W21:B30",   // black pawn on the crowning row
+		"W:W21:W22",   // white given twice
+		"B:B1:B2",     // black given twice
+		"W:W21:B12-1", // reversed range
+	}
+	for _, test := range tests {
+		if _, _, err := ParseFEN(8, test); err == nil {
+			t.Errorf("expected error parsing %q", test)
+		}
+	}
+	if _, _, err := ParseFEN(7, "W:W21:B1"); err == nil {
+		t.Errorf("expected error for invalid board size")
+	}
+}
+
+func TestGameFEN(t *testing.T) {
+	g := NewCustomGame(AmericanVariant, nil, BlackColor)
+	if fen := g.FEN(); fen != "B:W21,22,23,24,25,26,27,28,29,30,31,32:B1,2,3,4,5,6,7,8,9,10,11,12" {
+		t.Errorf("wrong FEN for the initial position %q", fen)
+	}
+
+	g, err := NewGameFromFEN(InternationalVariant, "B:W31-50:B1-20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.ToPlay() != BlackColor || g.Board().Size() != 10 || g.Board().PieceCount().WhitePawns != 20 {
+		t.Errorf("wrong game from FEN\n%v", g)
+	}
+
+	if _, err := NewGameFromFEN(BrazilianVariant, "W:W31-50:B1-20"); err == nil {
+		t.Errorf("expected error for squares out of the 8x8 board")
+	}
+}
@@ -84,15 +84,18 @@ type UndoInfo struct {
 }
 
 type Game struct {
-	stagnantTurnsToDraw int16 // stagnant here means no captures and no pawn moves
-	board               *Board
-	toPlay              Color
-	state               gameState
+	variant Variant
+	board   *Board
+	toPlay  Color
+	state   gameState
+	// how many times each position (see Hash) occurred in the game, to detect repetitions
+	positions map[uint64]int16
 }
 
 func (g *Game) String() string {
 	return fmt.Sprintf(
-		"{ToPlay: %v, turnsSinceCapture: %v, turnsSincePawnMove: %v, turnsInSpecialEnding: %v, Board:\n%v\n}",
+		"{Variant: %v, ToPlay: %v, turnsSinceCapture: %v, turnsSincePawnMove: %v, turnsInSpecialEnding: %v, Board:\n%v\n}",
+		g.variant,
 		g.toPlay,
 		g.state.turnsSinceCapture,
 		g.state.turnsSincePawnMove,
@@ -101,18 +104,26 @@ func (g *Game) String() string {
 	)
 }
 
-func NewCustomGame(stagnantTurnsToDraw int16, initialBoard *Board, initalPlayer Color) *Game {
+// Panics if the initial board isn't of the size of the variant
+func NewCustomGame(variant Variant, initialBoard *Board, initalPlayer Color) *Game {
 	var g Game
 
+	g.variant = variant
+
+	size := variant.Size
+	if size == 0 {
+		size = DefaultBoardSize
+	}
 	if initialBoard == nil {
-		g.board = new(Board)
+		g.board = NewBoard(size)
 		PlaceInitialPieces(g.board)
 	} else {
+		if initialBoard.Size() != size {
+			panic(fmt.Sprintf("board of size %d for the %s variant, of size %d", initialBoard.Size(), variant.Name, size))
+		}
 		g.board = initialBoard
 	}
 
-	g.stagnantTurnsToDraw = stagnantTurnsToDraw
-
 	g.toPlay = initalPlayer
 
 	g.state.turnsSinceCapture = 0
@@ -122,17 +133,23 @@ func NewCustomGame(stagnantTurnsToDraw int16, initialBoard *Board, initalPlayer
 
 	g.BoardChanged(nil)
 
+	g.positions = map[uint64]int16{g.Hash(): 1}
+
 	return &g
 }
 
 func NewGame() *Game {
-	return NewCustomGame(20, nil, WhiteColor)
+	return NewCustomGame(BrazilianVariant, nil, WhiteColor)
 }
 
 func (g *Game) Board() *Board {
 	return g.board
 }
 
+func (g *Game) Variant() Variant {
+	return g.variant
+}
+
 func (g *Game) ToPlay() Color {
 	return g.toPlay
 }
@@ -155,6 +172,7 @@ func (g *Game) DoPly(p Ply) (*UndoInfo, error) {
 	prevState := g.state
 	g.toPlay = g.toPlay.Opposite()
 	g.BoardChanged(p)
+	g.positions[g.Hash()]++
 
 	return &UndoInfo{plyDone: p, prevState: prevState}, nil
 }
@@ -170,11 +188,16 @@ func (g *Game) Result() GameResult {
 		return WhiteWonResult
 	}
 
-	if g.state.turnsInSpecialEnding == 5 {
+	if toDraw := g.variant.SpecialEndingTurnsToDraw; toDraw > 0 && g.state.turnsInSpecialEnding >= toDraw {
+		return DrawResult
+	}
+
+	if toDraw := g.variant.RepetitionsToDraw; toDraw > 0 && g.Repetitions() >= toDraw {
 		return DrawResult
 	}
 
-	if g.state.turnsSincePawnMove >= g.stagnantTurnsToDraw && g.state.turnsSinceCapture >= g.stagnantTurnsToDraw {
+	// stagnant here means no captures and no pawn moves
+	if toDraw := g.variant.StagnantTurnsToDraw; toDraw > 0 && g.state.turnsSincePawnMove >= toDraw && g.state.turnsSinceCapture >= toDraw {
 		return DrawResult
 	}
 
@@ -190,6 +213,12 @@ func (g *Game) Result() GameResult {
 }
 
 func (g *Game) UndoPly(undo *UndoInfo) {
+	hash := g.Hash()
+	if g.positions[hash] <= 1 {
+		delete(g.positions, hash)
+	} else {
+		g.positions[hash]--
+	}
 	UndoInstructions(g.board, undo.plyDone)
 	g.toPlay = g.toPlay.Opposite()
 	g.state = undo.prevState
@@ -197,7 +226,11 @@ func (g *Game) UndoPly(undo *UndoInfo) {
 
 func (g *Game) Copy() *Game {
 	// plies shallow-copied
-	// board deep-copied
+	// board and positions deep-copied
+	positions := make(map[uint64]int16, len(g.positions))
+	for hash, count := range g.positions {
+		positions[hash] = count
+	}
 	return &Game{
 		state: gameState{
 			turnsSinceCapture:    g.state.turnsSinceCapture,
@@ -205,12 +238,26 @@ func (g *Game) Copy() *Game {
 			turnsInSpecialEnding: g.state.turnsInSpecialEnding,
 			plies:                g.state.plies,
 		},
-		stagnantTurnsToDraw: g.stagnantTurnsToDraw,
-		board:               g.board.Copy(),
-		toPlay:              g.toPlay,
+		variant:   g.variant,
+		board:     g.board.Copy(),
+		toPlay:    g.toPlay,
+		positions: positions,
 	}
 }
 
+// Zobrist hash of the position: the pieces on the board and the player to move
+func (g *Game) Hash() uint64 {
+	if g.toPlay == BlackColor {
+		return g.board.Hash() ^ zobristBlackToPlayKey
+	}
+	return g.board.Hash()
+}
+
+// How many times the current position occurred in the game, counting the current one
+func (g *Game) Repetitions() int16 {
+	return g.positions[g.Hash()]
+}
+
 func (g *Game) Equals(o *Game) bool {
 	if g == nil && o == nil {
 		return true
@@ -268,7 +315,7 @@ func (g *Game) BoardChanged(ply Ply) {
 }
 
 func (g *Game) generatePlies() []Ply {
-	return GeneratePlies(make([]Ply, 0, 10), g.board, g.toPlay)
+	return GeneratePlies(make([]Ply, 0, 10), g.board, g.toPlay, g.variant)
 }
 
 func (g *Game) Plies() []Ply {
@@ -56,7 +56,7 @@ func TestWhiteWinsByNoBlackPieces(t *testing.T) {
 		...o
 	`)
 	t.Log("\n" + b.String())
-	g := NewCustomGame(20, b, WhiteColor)
+	g := NewCustomGame(BrazilianVariant, b, WhiteColor)
 	assertGameResult(t, g, WhiteWonResult)
 }
 
@@ -70,7 +70,7 @@ func TestBlackWinsByNoWhitePieces(t *testing.T) {
 		.
 	`)
 	t.Log("\n" + b.String())
-	g := NewCustomGame(20, b, WhiteColor)
+	g := NewCustomGame(BrazilianVariant, b, WhiteColor)
 	assertGameResult(t, g, BlackWonResult)
 }
 
@@ -86,7 +86,7 @@ func TestWhiteWinsByNoBlackPlies(t *testing.T) {
 		x.o
 	`)
 	t.Log("\n" + b.String())
-	g := NewCustomGame(20, b, BlackColor)
+	g := NewCustomGame(BrazilianVariant, b, BlackColor)
 	assertGameResult(t, g, WhiteWonResult)
 }
 
@@ -100,7 +100,7 @@ func TestBlackWinsByNoWhitePlies(t *testing.T) {
 		.....o
 	`)
 	t.Log("\n" + b.String())
-	g := NewCustomGame(20, b, WhiteColor)
+	g := NewCustomGame(BrazilianVariant, b, WhiteColor)
 	t.Log()
 	assertGameResult(t, g, BlackWonResult)
 }
@@ -129,7 +129,9 @@ func TestDrawByNoCaptureNorKingMovesForNTurns(t *testing.T) {
 		ooooooo
 	`)
 
-	g := NewCustomGame(3, b, WhiteColor)
+	v := BrazilianVariant
+	v.StagnantTurnsToDraw = 3
+	g := NewCustomGame(v, b, WhiteColor)
 	assertGameResult(t, g, PlayingResult)
 
 	var err error
@@ -278,7 +280,7 @@ func TestDrawByNoCaptureNorKingMovesForNTurns(t *testing.T) {
 }
 
 func assertSpecialEnding(t *testing.T, b *Board) {
-	g := NewCustomGame(20, b, WhiteColor)
+	g := NewCustomGame(BrazilianVariant, b, WhiteColor)
 	t.Log("\n" + g.Board().String())
 	// 1 turn in special ending
 	assertGameResult(t, g, PlayingResult)
@@ -472,3 +474,145 @@ func TestMarshalUnmarshalGameResult(t *testing.T) {
 		}
 	}
 }
+
+func TestGameCaptureRules(t *testing.T) {
+	b := DecodeBoard(`
+		.
+		...x.x
+		.
+		.....x
+		......o
+		.
+		.x
+		o
+	`)
+
+	v := BrazilianVariant
+
+	g := NewCustomGame(v, b.Copy(), WhiteColor)
+	if len(g.Plies()) != 2 {
+		t.Errorf("best mandatory: expected 2 plies, got %v", g.Plies())
+	}
+
+	v.BestRule = BestNotMandatory
+	g = NewCustomGame(v, b.Copy(), WhiteColor)
+	if len(g.Plies()) != 3 {
+		t.Errorf("best not mandatory: expected 3 plies, got %v", g.Plies())
+	}
+
+	v.CaptureRule = CapturesNotMandatory
+	g = NewCustomGame(v, b.Copy(), WhiteColor)
+	h := g.Copy()
+	if h.Variant() != v {
+		t.Errorf("copy should keep the game variant")
+	}
+	// 3 captures + 1 simple pawn move from (4, 6)
+	if len(h.Plies()) != 4 {
+		t.Errorf("captures not mandatory: expected 4 plies, got %v", h.Plies())
+	}
+}
+
+func TestInternationalGame(t *testing.T) {
+	g := NewCustomGame(InternationalVariant, nil, WhiteColor)
+	if g.Board().Size() != 10 {
+		t.Fatalf("expected a 10x10 board, got %dx%d", g.Board().Size(), g.Board().Size())
+	}
+	// 5 pawns in the front row that can move 2 ways, except the one at the edge
+	if n := len(g.Plies()); n != 9 {
+		t.Errorf("expected 9 initial plies, got %d", n)
+	}
+
+	var states []*Game
+	var undos []*UndoInfo
+	for !g.Result().Over() {
+		states = append(states, g.Copy())
+		plies := g.Plies()
+		undo, err := g.DoPly(plies[rand.Intn(len(plies))])
+		if err != nil {
+			t.Fatal(err)
+		}
+		undos = append(undos, undo)
+	}
+	for i := len(states) - 1; i >= 0; i-- {
+		g.UndoPly(undos[i])
+		if !g.Equals(states[i]) {
+			t.Fatalf("game differs after undoing ply %d", i)
+		}
+	}
+}
+
+func TestNewCustomGameBoardSize(t *testing.T) {
+	if g := NewCustomGame(InternationalVariant, NewBoard(10), WhiteColor); g.Board().Size() != 10 {
+		t.Errorf("expected the board given, got size %d", g.Board().Size())
+	}
+	for _, tc := range []struct {
+		v    Variant
+		size byte
+	}{
+		{InternationalVariant, 8},
+		{BrazilianVariant, 10},
+		{Variant{}, 10},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a %dx%d board for the %q variant to panic", tc.size, tc.size, tc.v.Name)
+				}
+			}()
+			NewCustomGame(tc.v, NewBoard(tc.size), WhiteColor)
+		}()
+	}
+}
+
+func TestDrawByRepetition(t *testing.T) {
+	b := DecodeBoard(`
+	  .......#
+		.
+		.
+		.
+		.
+		.
+		.
+		@
+	`)
+	g := NewCustomGame(AmericanVariant, b, WhiteColor)
+	shuffle := []Ply{
+		{MakeMoveInstruction(7, 0, 6, 1)},
+		{MakeMoveInstruction(0, 7, 1, 6)},
+		{MakeMoveInstruction(6, 1, 7, 0)},
+		{MakeMoveInstruction(1, 6, 0, 7)},
+	}
+	start := g.Hash()
+
+	var undo *UndoInfo
+	for i := 0; i < 2; i++ {
+		for _, p := range shuffle {
+			assertGameResult(t, g, PlayingResult)
+			var err error
+			if undo, err = g.DoPly(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if g.Hash() != start {
+			t.Errorf("expected to be back at the start position")
+		}
+		if g.Repetitions() != int16(i+2) {
+			t.Errorf("expected the start position to have occurred %d times, got %d", i+2, g.Repetitions())
+		}
+	}
+	// third time in the same position
+	assertGameResult(t, g, DrawResult)
+
+	g.UndoPly(undo)
+	assertGameResult(t, g, PlayingResult)
+	if _, err := g.DoPly(shuffle[3]); err != nil {
+		t.Fatal(err)
+	}
+	assertGameResult(t, g, DrawResult)
+
+	// copies keep their own count
+	c := g.Copy()
+	c.UndoPly(undo)
+	assertGameResult(t, c, PlayingResult)
+	assertGameResult(t, g, DrawResult)
+}
@@ -135,14 +135,15 @@ func PliesEquals(ps []Ply, qs []Ply) bool {
 // simple plies are ones not involving any captures, where the piece just moves
 
 func generateSimplePawnPlies(ps []Ply, b *Board, row, col byte, color Color) []Ply {
+	size := b.Size()
 	drow := byte(int8(row) + forward[color])
-	if drow >= 8 {
+	if drow >= size {
 		return ps
 	}
-	crown := crowningRow[color] == drow
+	crown := crowningRow(color, size) == drow
 	for _, dir := range offBoth {
 		dcol := byte(int8(col) + dir)
-		if dcol >= 8 || b.IsOccupied(drow, dcol) {
+		if dcol >= size || b.IsOccupied(drow, dcol) {
 			continue
 		}
 		var is []Instruction
@@ -155,19 +156,24 @@ func generateSimplePawnPlies(ps []Ply, b *Board, row, col byte, color Color) []P
 	return ps
 }
 
-func generateSimpleKingPlies(ps []Ply, b *Board, row, col byte, color Color) []Ply {
+func generateSimpleKingPlies(ps []Ply, b *Board, row, col byte, color Color, v *Variant) []Ply {
+	size := b.Size()
 	for _, roff := range offBoth {
 		for _, coff := range offBoth {
 			dist := int8(1)
 			for {
 				drow, dcol := byte(int8(row)+dist*roff), byte(int8(col)+dist*coff)
-				if drow >= 8 || dcol >= 8 || b.IsOccupied(drow, dcol) {
+				if drow >= size || dcol >= size || b.IsOccupied(drow, dcol) {
 					break
 				}
 
 				is := []Instruction{MakeMoveInstruction(row, col, drow, dcol)}
 				ps = append(ps, Ply(is))
 
+				if !v.FlyingKings {
+					break
+				}
+
 				dist++
 			}
 		}
@@ -176,9 +182,10 @@ func generateSimpleKingPlies(ps []Ply, b *Board, row, col byte, color Color) []P
 	return ps
 }
 
-func generateSimplePlies(ps []Ply, b *Board, player Color) []Ply {
-	for row := byte(0); row < 8; row++ {
-		for col := byte(0); col < 8; col++ {
+func generateSimplePlies(ps []Ply, b *Board, player Color, v *Variant) []Ply {
+	size := b.Size()
+	for row := byte(0); row < size; row++ {
+		for col := byte(0); col < size; col++ {
 			if !b.IsOccupied(row, col) {
 				continue
 			}
@@ -191,7 +198,7 @@ func generateSimplePlies(ps []Ply, b *Board, player Color) []Ply {
 			if kind == PawnKind {
 				ps = generateSimplePawnPlies(ps, b, row, col, color)
 			} else {
-				ps = generateSimpleKingPlies(ps, b, row, col, color)
+				ps = generateSimpleKingPlies(ps, b, row, col, color, v)
 			}
 		}
 	}
@@ -204,14 +211,20 @@ func generateSimplePlies(ps []Ply, b *Board, player Color) []Ply {
 // (we need to do a tree search in order to generate all possibilities of
 // sequential captures, and we do that by backtracking)
 
-func followPawnCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte, color Color) []Ply {
+func followPawnCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte, color Color, v *Variant) []Ply {
+	size := b.Size()
+	crowning := crowningRow(color, size)
+
 	// sink: there are no more captures available from here
 	sink := true
 
 	for _, roff := range offBoth {
+		if !v.PawnsCaptureBackward && roff != forward[color] {
+			continue
+		}
 		for _, coff := range offBoth {
 			drow, dcol := byte(int8(row)+2*roff), byte(int8(col)+2*coff)
-			if drow >= 8 || dcol >= 8 || b.IsOccupied(drow, dcol) {
+			if drow >= size || dcol >= size || b.IsOccupied(drow, dcol) {
 				continue
 			}
 			mrow, mcol := byte(int8(row)+roff), byte(int8(col)+coff)
@@ -222,6 +235,9 @@ func followPawnCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte,
 			if mcolor == color {
 				continue
 			}
+			if mkind == KingKind && !v.PawnsCaptureKings {
+				continue
+			}
 
 			sink = false
 
@@ -231,7 +247,17 @@ func followPawnCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte,
 			b.Move(row, col, drow, dcol)
 			b.Clear(mrow, mcol)
 
-			ps = followPawnCaptures(ps, stack, b, drow, dcol, color)
+			if v.CrownMidCapture && drow == crowning {
+				// the crown instruction goes in the middle of the ply,
+				// and from here on it captures like a king
+				stack = append(stack, MakeCrownInstruction(drow, dcol))
+				b.Crown(drow, dcol)
+				ps = followKingCaptures(ps, stack, b, drow, dcol, color, v)
+				b.Uncrown(drow, dcol)
+				stack = stack[:len(stack)-1]
+			} else {
+				ps = followPawnCaptures(ps, stack, b, drow, dcol, color, v)
+			}
 
 			// undo
 			b.Set(mrow, mcol, mcolor, mkind)
@@ -244,7 +270,7 @@ func followPawnCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte,
 	// stack is nil at the first call when no captures have been made yet
 	if sink && stack != nil {
 		isLen := len(stack)
-		crown := row == crowningRow[color]
+		crown := row == crowning
 		if crown {
 			isLen += 1
 		}
@@ -258,7 +284,8 @@ func followPawnCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte,
 	return ps
 }
 
-func followKingCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte, player Color) []Ply {
+func followKingCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte, player Color, v *Variant) []Ply {
+	size := b.Size()
 	sink := true
 
 	for _, roff := range offBoth {
@@ -270,15 +297,20 @@ func followKingCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte,
 			var crow, ccol byte
 			var ccolor Color
 			var ckind Kind
+			var cdist int8
 
 			dist := int8(1)
 			for {
+				// non-flying kings can only capture an adjacent piece and land right after it
+				if !v.FlyingKings && dist > cdist+1 {
+					break
+				}
 
 				// i for [i]teration row and col, for lack of a better single-letter abbreviation
 				// TODO implement by irow, icol = irow+roff, icol+coff? would it be any more efficient?
 				irow, icol := byte(int8(row)+dist*roff), byte(int8(col)+dist*coff)
 
-				if irow >= 8 || icol >= 8 {
+				if irow >= size || icol >= size {
 					break
 				}
 
@@ -292,6 +324,7 @@ func followKingCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte,
 						pastCapture = true
 						crow, ccol = irow, icol
 						ccolor, ckind = icolor, ikind
+						cdist = dist
 					}
 				} else if pastCapture {
 					// this is a destination
@@ -303,7 +336,7 @@ func followKingCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte,
 					b.Move(row, col, irow, icol)
 					b.Clear(crow, ccol)
 
-					ps = followKingCaptures(ps, stack, b, irow, icol, player)
+					ps = followKingCaptures(ps, stack, b, irow, icol, player, v)
 
 					// undo
 					b.Set(crow, ccol, ccolor, ckind)
@@ -327,9 +360,10 @@ func followKingCaptures(ps []Ply, stack []Instruction, b *Board, row, col byte,
 	return ps
 }
 
-func generateCapturePlies(ps []Ply, b *Board, player Color) []Ply {
-	for row := byte(0); row < 8; row++ {
-		for col := byte(0); col < 8; col++ {
+func generateCapturePlies(ps []Ply, b *Board, player Color, v *Variant) []Ply {
+	size := b.Size()
+	for row := byte(0); row < size; row++ {
+		for col := byte(0); col < size; col++ {
 			if !b.IsOccupied(row, col) {
 				continue
 			}
@@ -340,19 +374,91 @@ func generateCapturePlies(ps []Ply, b *Board, player Color) []Ply {
 			}
 
 			if kind == PawnKind {
-				ps = followPawnCaptures(ps, nil, b, row, col, color)
+				ps = followPawnCaptures(ps, nil, b, row, col, color, v)
 			} else {
-				ps = followKingCaptures(ps, nil, b, row, col, color)
+				ps = followKingCaptures(ps, nil, b, row, col, color, v)
 			}
 		}
 	}
 	return ps
 }
 
-func GeneratePlies(ps []Ply, b *Board, player Color) []Ply {
-	ps = generateCapturePlies(ps, b, player)
-	if len(ps) == 0 {
-		ps = generateSimplePlies(ps, b, player)
+func captureCount(p Ply) int {
+	n := 0
+	for _, ins := range p {
+		if ins.t == CaptureInstruction {
+			n++
+		}
+	}
+	return n
+}
+
+func kingCaptureCount(p Ply) int {
+	n := 0
+	for _, ins := range p {
+		if ins.t == CaptureInstruction && Kind(ins.d[1]) == KingKind {
+			n++
+		}
+	}
+	return n
+}
+
+// the earlier the first king is captured, the higher the value (0 when no king is captured)
+func earliestKingCapture(p Ply) int {
+	n := captureCount(p)
+	i := 0
+	for _, ins := range p {
+		if ins.t != CaptureInstruction {
+			continue
+		}
+		if Kind(ins.d[1]) == KingKind {
+			return n - i
+		}
+		i++
+	}
+	return 0
+}
+
+// keeps only the plies with the highest value (in place)
+func filterMax(ps []Ply, value func(Ply) int) []Ply {
+	best := 0
+	for i, p := range ps {
+		if n := value(p); i == 0 || n > best {
+			best = n
+		}
+	}
+	kept := ps[:0]
+	for _, p := range ps {
+		if value(p) == best {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// ps must be capture plies generated for the given board
+func filterQualityCaptures(ps []Ply, b *Board) []Ply {
+	ps = filterMax(ps, func(p Ply) int {
+		_, kind := b.Get(p[0].row, p[0].col)
+		return int(kind)
+	})
+	ps = filterMax(ps, kingCaptureCount)
+	ps = filterMax(ps, earliestKingCapture)
+	return ps
+}
+
+func GeneratePlies(ps []Ply, b *Board, player Color, v Variant) []Ply {
+	// only the plies generated here are filtered, whatever was in ps before is kept
+	start := len(ps)
+	ps = generateCapturePlies(ps, b, player, &v)
+	if v.BestRule == BestMandatory {
+		ps = ps[:start+len(filterMax(ps[start:], captureCount))]
+	}
+	if v.QualityPriority {
+		ps = ps[:start+len(filterQualityCaptures(ps[start:], b))]
+	}
+	if len(ps) == start || v.CaptureRule == CapturesNotMandatory {
+		ps = generateSimplePlies(ps, b, player, &v)
 	}
 	return ps
 }
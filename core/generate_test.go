@@ -80,7 +80,7 @@ func TestSimplePawnMove(t *testing.T) {
 
 	t.Log("\n" + b.String())
 
-	blackPliesGot := generateSimplePlies(nil, b, BlackColor)
+	blackPliesGot := generateSimplePlies(nil, b, BlackColor, &BrazilianVariant)
 
 	blackPliesWant := []Ply{
 		{MakeMoveInstruction(1, 1, 2, 2)},
@@ -114,7 +114,7 @@ func TestSimplePawnMove(t *testing.T) {
 			MakeCrownInstruction(0, 4),
 		},
 	}
-	whitePliesGot := generateSimplePlies(nil, b, WhiteColor)
+	whitePliesGot := generateSimplePlies(nil, b, WhiteColor, &BrazilianVariant)
 
 	assertEqualPlies(t, whitePliesGot, whitePliesWant)
 }
@@ -132,7 +132,7 @@ func TestSimpleKingMove(t *testing.T) {
 
 	t.Log("\n" + b.String())
 
-	whitePliesGot := generateSimplePlies(nil, b, WhiteColor)
+	whitePliesGot := generateSimplePlies(nil, b, WhiteColor, &BrazilianVariant)
 
 	whitePliesWant := []Ply{
 		//
@@ -181,7 +181,7 @@ func TestSimpleKingMove(t *testing.T) {
 		{MakeMoveInstruction(2, 2, 1, 1)},
 		{MakeMoveInstruction(2, 2, 0, 0)},
 	}
-	blackPliesGot := generateSimplePlies(nil, b, BlackColor)
+	blackPliesGot := generateSimplePlies(nil, b, BlackColor, &BrazilianVariant)
 
 	assertEqualPlies(t, blackPliesGot, blackPliesWant)
 }
@@ -200,7 +200,7 @@ func TestCapturePawnMove(t *testing.T) {
 
 	t.Log("\n" + b.String())
 
-	blackPliesGot := generateCapturePlies(nil, b, BlackColor)
+	blackPliesGot := generateCapturePlies(nil, b, BlackColor, &BrazilianVariant)
 
 	blackPliesWant := []Ply{
 		{
@@ -240,7 +240,7 @@ func TestCapturePawnMove(t *testing.T) {
 			MakeCrownInstruction(0, 2),
 		},
 	}
-	whitePliesGot := generateCapturePlies(nil, b, WhiteColor)
+	whitePliesGot := generateCapturePlies(nil, b, WhiteColor, &BrazilianVariant)
 
 	assertEqualPlies(t, whitePliesGot, whitePliesWant)
 }
@@ -268,7 +268,7 @@ func TestCaptureThroughCrowningRowDoesntCrown(t *testing.T) {
 			MakeCaptureInstruction(1, 2, BlackColor, PawnKind),
 		},
 	}
-	pliesGot := generateCapturePlies(nil, b, WhiteColor)
+	pliesGot := generateCapturePlies(nil, b, WhiteColor, &BrazilianVariant)
 
 	assertEqualPlies(t, pliesGot, pliesWant)
 }
@@ -279,7 +279,7 @@ func TestCaptureKingMoveOneDiagonal(t *testing.T) {
 	b.Set(3, 3, WhiteColor, KingKind)
 	b.Set(5, 5, BlackColor, PawnKind)
 
-	pliesGot := generateCapturePlies(nil, b, WhiteColor)
+	pliesGot := generateCapturePlies(nil, b, WhiteColor, &BrazilianVariant)
 	pliesWant := []Ply{
 		{
 			MakeMoveInstruction(3, 3, 6, 6),
@@ -346,7 +346,7 @@ func TestAllowOverPreviousTile(t *testing.T) {
 			MakeCrownInstruction(0, 7),
 		},
 	}
-	pliesGot := generateCapturePlies(nil, b, WhiteColor)
+	pliesGot := generateCapturePlies(nil, b, WhiteColor, &BrazilianVariant)
 
 	assertEqualPlies(t, pliesGot, pliesWant)
 }
@@ -544,3 +544,148 @@ func TestPliesEquals(t *testing.T) {
 		}
 	}
 }
+
+func TestBestRule(t *testing.T) {
+	b := DecodeBoard(`
+		.
+		...x.x
+		.
+		.....x
+		......o
+		.
+		.x
+		o
+	`)
+	t.Log("\n" + b.String())
+
+	shortCapture := Ply{
+		MakeMoveInstruction(7, 0, 5, 2),
+		MakeCaptureInstruction(6, 1, BlackColor, PawnKind),
+	}
+	longCapture := Ply{
+		MakeMoveInstruction(4, 6, 2, 4),
+		MakeCaptureInstruction(3, 5, BlackColor, PawnKind),
+		MakeMoveInstruction(2, 4, 0, 2),
+		MakeCaptureInstruction(1, 3, BlackColor, PawnKind),
+		MakeCrownInstruction(0, 2),
+	}
+	otherLongCapture := Ply{
+		MakeMoveInstruction(4, 6, 2, 4),
+		MakeCaptureInstruction(3, 5, BlackColor, PawnKind),
+		MakeMoveInstruction(2, 4, 0, 6),
+		MakeCaptureInstruction(1, 5, BlackColor, PawnKind),
+		MakeCrownInstruction(0, 6),
+	}
+
+	v := BrazilianVariant
+
+	v.BestRule = BestNotMandatory
+	got := GeneratePlies(nil, b, WhiteColor, v)
+	assertEqualPlies(t, got, []Ply{shortCapture, longCapture, otherLongCapture})
+
+	v.BestRule = BestMandatory
+	got = GeneratePlies(nil, b, WhiteColor, v)
+	assertEqualPlies(t, got, []Ply{longCapture, otherLongCapture})
+}
+
+func TestCapturesNotMandatory(t *testing.T) {
+	b := DecodeBoard(`
+		.
+		.
+		.
+		.
+		.
+		.
+		.x
+		o
+	`)
+	t.Log("\n" + b.String())
+
+	capture := Ply{
+		MakeMoveInstruction(7, 0, 5, 2),
+		MakeCaptureInstruction(6, 1, BlackColor, PawnKind),
+	}
+
+	v := BrazilianVariant
+
+	got := GeneratePlies(nil, b, WhiteColor, v)
+	assertEqualPlies(t, got, []Ply{capture})
+
+	// the pawn at (7, 0) can't move anywhere but the capture
+	b.Set(4, 4, WhiteColor, PawnKind)
+	v.CaptureRule = CapturesNotMandatory
+	got = GeneratePlies(nil, b, WhiteColor, v)
+	assertEqualPlies(t, got, []Ply{
+		capture,
+		{MakeMoveInstruction(4, 4, 3, 3)},
+		{MakeMoveInstruction(4, 4, 3, 5)},
+	})
+}
+
+func TestGeneratePliesKeepsPreviousPlies(t *testing.T) {
+	b := DecodeBoard(`
+		.
+		.
+		.
+		.
+		.
+		.
+		.x
+		o
+	`)
+	previous := Ply{MakeMoveInstruction(1, 1, 2, 2)}
+	got := GeneratePlies([]Ply{previous}, b, WhiteColor, BrazilianVariant)
+	if len(got) != 2 || !got[0].Equals(previous) {
+		t.Errorf("expected the previous ply to be kept, got %v", got)
+	}
+}
+
+func TestGeneratePlies10x10(t *testing.T) {
+	b := NewBoard(10)
+	b.Set(9, 0, WhiteColor, KingKind)
+	b.Set(5, 4, BlackColor, PawnKind)
+	b.Set(1, 9, BlackColor, PawnKind)
+
+	t.Log("\n" + b.String())
+
+	whiteWant := []Ply{
+		{
+			MakeMoveInstruction(9, 0, 4, 5),
+			MakeCaptureInstruction(5, 4, BlackColor, PawnKind),
+		},
+		{
+			MakeMoveInstruction(9, 0, 3, 6),
+			MakeCaptureInstruction(5, 4, BlackColor, PawnKind),
+		},
+		{
+			MakeMoveInstruction(9, 0, 2, 7),
+			MakeCaptureInstruction(5, 4, BlackColor, PawnKind),
+		},
+		{
+			MakeMoveInstruction(9, 0, 1, 8),
+			MakeCaptureInstruction(5, 4, BlackColor, PawnKind),
+		},
+		{
+			MakeMoveInstruction(9, 0, 0, 9),
+			MakeCaptureInstruction(5, 4, BlackColor, PawnKind),
+		},
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, InternationalVariant), whiteWant)
+
+	blackWant := []Ply{
+		{MakeMoveInstruction(5, 4, 6, 3)},
+		{MakeMoveInstruction(5, 4, 6, 5)},
+		{MakeMoveInstruction(1, 9, 2, 8)},
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, BlackColor, InternationalVariant), blackWant)
+
+	b.Set(8, 1, BlackColor, PawnKind)
+	b.Clear(9, 0)
+	b.Clear(5, 4)
+	b.Clear(1, 9)
+	crowning := []Ply{
+		{MakeMoveInstruction(8, 1, 9, 0), MakeCrownInstruction(9, 0)},
+		{MakeMoveInstruction(8, 1, 9, 2), MakeCrownInstruction(9, 2)},
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, BlackColor, InternationalVariant), crowning)
+}
@@ -0,0 +1,117 @@
+package core
+
+import "fmt"
+
+type historyEntry struct {
+	ply  Ply
+	undo *UndoInfo
+}
+
+// History wraps a game, recording every ply done so they can be listed,
+// undone, redone or jumped to. Doing a new ply after undoing some discards
+// the undone ones, unless it's the same as the next one recorded.
+type History struct {
+	game    *Game
+	entries []historyEntry
+	// number of recorded plies currently performed on the game
+	current int
+}
+
+// The history takes over the game, which from then on should only be changed through it
+func NewHistory(g *Game) *History {
+	return &History{game: g}
+}
+
+func (h *History) Game() *Game {
+	return h.game
+}
+
+func (h *History) DoPly(p Ply) error {
+	if h.current < len(h.entries) && h.entries[h.current].ply.Equals(p) {
+		return h.Redo()
+	}
+	undo, err := h.game.DoPly(p)
+	if err != nil {
+		return err
+	}
+	h.entries = append(h.entries[:h.current], historyEntry{ply: p, undo: undo})
+	h.current++
+	return nil
+}
+
+func (h *History) CanUndo() bool {
+	return h.current > 0
+}
+
+func (h *History) CanRedo() bool {
+	return h.current < len(h.entries)
+}
+
+func (h *History) Undo() error {
+	if !h.CanUndo() {
+		return fmt.Errorf("history: nothing to undo")
+	}
+	h.current--
+	h.game.UndoPly(h.entries[h.current].undo)
+	return nil
+}
+
+func (h *History) Redo() error {
+	if !h.CanRedo() {
+		return fmt.Errorf("history: nothing to redo")
+	}
+	entry := &h.entries[h.current]
+	undo, err := h.game.DoPly(entry.ply)
+	if err != nil {
+		return err
+	}
+	entry.undo = undo
+	h.current++
+	return nil
+}
+
+// Undoes or redoes plies until exactly i plies from the start are performed
+func (h *History) GoTo(i int) error {
+	if i < 0 || i > len(h.entries) {
+		return fmt.Errorf("history: ply index %d out of range [0, %d]", i, len(h.entries))
+	}
+	for h.current > i {
+		if err := h.Undo(); err != nil {
+			return err
+		}
+	}
+	for h.current < i {
+		if err := h.Redo(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Number of plies performed from the start
+func (h *History) Current() int {
+	return h.current
+}
+
+// Number of plies recorded, including the ones that were undone and can be redone
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// The plies performed from the start, in order
+func (h *History) Plies() []Ply {
+	return h.recordedPlies(h.current)
+}
+
+// All the plies recorded, including the ones that were undone and can be redone
+func (h *History) AllPlies() []Ply {
+	return h.recordedPlies(len(h.entries))
+}
+
+func (h *History) recordedPlies(n int) []Ply {
+	plies := make([]Ply, n)
+	for i := range plies {
+		plies[i] = h.entries[i].ply
+	}
+	return plies
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHistoryUndoRedo(t *testing.T) {
+	g := NewGame()
+	h := NewHistory(g)
+
+	var snapshots []*Game
+	snapshots = append(snapshots, g.Copy())
+	for i := 0; i < 20 && !g.Result().Over(); i++ {
+		ps := g.Plies()
+		if err := h.DoPly(ps[rand.Intn(len(ps))]); err != nil {
+			t.Fatal(err)
+		}
+		snapshots = append(snapshots, g.Copy())
+	}
+	n := h.Len()
+	if h.Current() != n || len(h.Plies()) != n || h.CanRedo() {
+		t.Fatalf("expected %d plies performed, got %d", n, h.Current())
+	}
+
+	for h.CanUndo() {
+		if err := h.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		if !g.Equals(snapshots[h.Current()]) {
+			t.Errorf("wrong game after undoing to ply %d\n%v", h.Current(), g)
+		}
+	}
+	if err := h.Undo(); err == nil {
+		t.Errorf("expected error undoing at the start")
+	}
+	if len(h.Plies()) != 0 || len(h.AllPlies()) != n {
+		t.Errorf("undone plies should still be recorded")
+	}
+
+	for h.CanRedo() {
+		if err := h.Redo(); err != nil {
+			t.Fatal(err)
+		}
+		if !g.Equals(snapshots[h.Current()]) {
+			t.Errorf("wrong game after redoing to ply %d\n%v", h.Current(), g)
+		}
+	}
+	if err := h.Redo(); err == nil {
+		t.Errorf("expected error redoing at the end")
+	}
+}
+
+func TestHistoryGoTo(t *testing.T) {
+	g := NewGame()
+	h := NewHistory(g)
+
+	var snapshots []*Game
+	snapshots = append(snapshots, g.Copy())
+	for i := 0; i < 10; i++ {
+		if err := h.DoPly(g.Plies()[0]); err != nil {
+			t.Fatal(err)
+		}
+		snapshots = append(snapshots, g.Copy())
+	}
+
+	for _, i := range []int{3, 7, 0, 10, 5} {
+		if err := h.GoTo(i); err != nil {
+			t.Fatal(err)
+		}
+		if h.Current() != i || !g.Equals(snapshots[i]) {
+			t.Errorf("wrong game after going to ply %d\n%v", i, g)
+		}
+	}
+
+	for _, i := range []int{-1, 11} {
+		if err := h.GoTo(i); err == nil {
+			t.Errorf("expected error going to ply %d", i)
+		}
+	}
+}
+
+func TestHistoryBranch(t *testing.T) {
+	g := NewGame()
+	h := NewHistory(g)
+
+	for i := 0; i < 4; i++ {
+		if err := h.DoPly(g.Plies()[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all := h.AllPlies()
+
+	// doing the same ply that was undone keeps the rest of the record
+	h.GoTo(2)
+	if err := h.DoPly(all[2]); err != nil {
+		t.Fatal(err)
+	}
+	if h.Current() != 3 || h.Len() != 4 {
+		t.Errorf("expected to keep the recorded plies, current %d len %d", h.Current(), h.Len())
+	}
+
+	// doing a different one discards them
+	h.GoTo(2)
+	var other Ply
+	for _, p := range g.Plies() {
+		if !p.Equals(all[2]) {
+			other = p
+			break
+		}
+	}
+	if other == nil {
+		t.Fatal("expected more than one ply available")
+	}
+	if err := h.DoPly(other); err != nil {
+		t.Fatal(err)
+	}
+	if h.Current() != 3 || h.Len() != 3 || h.CanRedo() {
+		t.Errorf("expected the undone plies to be discarded, current %d len %d", h.Current(), h.Len())
+	}
+	if !PliesEquals(h.Plies()[:2], all[:2]) || !h.Plies()[2].Equals(other) {
+		t.Errorf("wrong plies after branching %v", h.Plies())
+	}
+
+	if err := h.DoPly(Ply{}); err == nil {
+		t.Errorf("expected error doing an empty ply")
+	}
+	if h.Len() != 3 {
+		t.Errorf("failed plies shouldn't be recorded")
+	}
+	if h.Game() != g {
+		t.Errorf("expected the wrapped game")
+	}
+}
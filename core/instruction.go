@@ -41,6 +41,28 @@ type Instruction struct {
 	d [2]byte
 }
 
+func (i Instruction) Type() InstructionType {
+	return i.t
+}
+
+func (i Instruction) Row() byte {
+	return i.row
+}
+
+func (i Instruction) Col() byte {
+	return i.col
+}
+
+// Only meaningful for a MoveInstruction
+func (i Instruction) Destination() (row, col byte) {
+	return i.d[0], i.d[1]
+}
+
+// Only meaningful for a CaptureInstruction
+func (i Instruction) Captured() (Color, Kind) {
+	return Color(i.d[0]), Kind(i.d[1])
+}
+
 func (i Instruction) Equals(o Instruction) bool {
 	return i.t == o.t &&
 		i.row == o.row &&
@@ -140,7 +162,7 @@ func (i *Instruction) Unserialize(bs []byte) error {
 	}
 	row := bs[1] - '0'
 	col := bs[2] - '0'
-	if row > 7 || col > 7 {
+	if row >= MaxBoardSize || col >= MaxBoardSize {
 		return fmt.Errorf("unmarshal instruction: out of bounds row or col")
 	}
 	i.row = row
@@ -158,7 +180,7 @@ func (i *Instruction) Unserialize(bs []byte) error {
 		}
 		drow := bs[3] - '0'
 		dcol := bs[4] - '0'
-		if drow > 7 || dcol > 7 {
+		if drow >= MaxBoardSize || dcol >= MaxBoardSize {
 			return fmt.Errorf("unmarshal instruction: move: out of bounds destination row or col")
 		}
 		i.d[0] = drow
@@ -238,7 +260,11 @@ func MakeCrownInstruction(row, col byte) Instruction {
 }
 
 func PerformInstructions(b *Board, is []Instruction) error {
+	size := b.Size()
 	for _, i := range is {
+		if i.row >= size || i.col >= size || (i.t == MoveInstruction && (i.d[0] >= size || i.d[1] >= size)) {
+			return fmt.Errorf("instruction %v out of bounds of %dx%d board", i, size, size)
+		}
 		switch i.t {
 		case MoveInstruction:
 			fromRow, fromCol := i.row, i.col
@@ -411,12 +411,12 @@ func TestInstructionUnmarshalIncorrectly(t *testing.T) {
 	tests := []string{
 		"\"b1234\"",
 		"\"m123\"",
-		"\"m1299\"",
+		"\"m12:9\"",
 		"\"c13w\"",
 		"\"c33bb\"",
 		"\"c33mp\"",
-		"\"c19wk\"",
-		"\"k08\"",
+		"\"c1:wk\"",
+		"\"k0:\"",
 		"\"m12345\"",
 		"\"c12bkk\"",
 		"\"k666\"",
@@ -457,3 +457,42 @@ func TestJsonMarshalUnmarshalInstruction(t *testing.T) {
 		}
 	}
 }
+
+func TestPerformInstructionsOutOfBounds(t *testing.T) {
+	tests := []Ply{
+		{MakeMoveInstruction(1, 1, 8, 8)},
+		{MakeCaptureInstruction(9, 1, WhiteColor, PawnKind)},
+		{MakeCrownInstruction(2, 8)},
+	}
+	for _, p := range tests {
+		b := new(Board)
+		b.Set(1, 1, WhiteColor, PawnKind)
+		if err := PerformInstructions(b, p); err == nil {
+			t.Errorf("expected error performing %v on 8x8 board", p)
+		}
+	}
+
+	b := NewBoard(10)
+	b.Set(1, 1, WhiteColor, PawnKind)
+	if err := PerformInstructions(b, Ply{MakeMoveInstruction(1, 1, 8, 8)}); err != nil {
+		t.Errorf("unexpected error on 10x10 board: %v", err)
+	}
+}
+
+func TestInstructionAccessors(t *testing.T) {
+	m := MakeMoveInstruction(1, 2, 3, 4)
+	if m.Type() != MoveInstruction || m.Row() != 1 || m.Col() != 2 {
+		t.Errorf("wrong type or source for %v", m)
+	}
+	if drow, dcol := m.Destination(); drow != 3 || dcol != 4 {
+		t.Errorf("wrong destination for %v", m)
+	}
+
+	c := MakeCaptureInstruction(5, 6, WhiteColor, KingKind)
+	if c.Type() != CaptureInstruction || c.Row() != 5 || c.Col() != 6 {
+		t.Errorf("wrong type or position for %v", c)
+	}
+	if color, kind := c.Captured(); color != WhiteColor || kind != KingKind {
+		t.Errorf("wrong captured piece for %v", c)
+	}
+}
@@ -5,7 +5,11 @@ import (
 )
 
 func rn8() uint8 {
-	return uint8(rand.Uint32() % 8)
+	return rnN(8)
+}
+
+func rnN(n byte) uint8 {
+	return uint8(rand.Uint32() % uint32(n))
 }
 
 func rnColor() Color {
@@ -18,9 +22,10 @@ func rnKind() Kind {
 
 func randomInoffensiveMove(b *Board, player Color) Ply {
 	var coords []coord
+	size := b.Size()
 
-	for row := byte(0); row < 8; row++ {
-		for col := byte(0); col < 8; col++ {
+	for row := byte(0); row < size; row++ {
+		for col := byte(0); col < size; col++ {
 			if !b.IsOccupied(row, col) {
 				continue
 			}
@@ -41,7 +46,7 @@ func randomInoffensiveMove(b *Board, player Color) Ply {
 
 	var drow, dcol byte
 	for {
-		drow, dcol = rn8(), rn8()
+		drow, dcol = rnN(size), rnN(size)
 		if !b.IsOccupied(drow, dcol) {
 			break
 		}
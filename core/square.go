@@ -0,0 +1,30 @@
+package core
+
+// Standard draughts notation numbers the playable (black) tiles from 1,
+// left to right and top to bottom, the top being the side where the
+// black pieces start. A 8x8 board has squares 1 to 32, a 10x10 one 1 to 50.
+
+func SquareCount(size byte) int {
+	return int(size) * int(size) / 2
+}
+
+func SquareNumber(size, row, col byte) (int, bool) {
+	if row >= size || col >= size || TileColor(row, col) != BlackColor {
+		return 0, false
+	}
+	return int(row)*int(size/2) + int(col/2) + 1, true
+}
+
+func SquareCoord(size byte, n int) (row, col byte, ok bool) {
+	if n < 1 || n > SquareCount(size) {
+		return 0, 0, false
+	}
+	perRow := int(size / 2)
+	n--
+	row = byte(n / perRow)
+	col = byte(n%perRow) * 2
+	if row%2 == 0 {
+		col++
+	}
+	return row, col, true
+}
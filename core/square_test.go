@@ -0,0 +1,62 @@
+package core
+
+import "testing"
+
+func TestSquareNumber(t *testing.T) {
+	type test struct {
+		size     byte
+		row, col byte
+		n        int
+	}
+	tests := []test{
+		{8, 0, 1, 1},
+		{8, 0, 7, 4},
+		{8, 1, 0, 5},
+		{8, 2, 5, 11},
+		{8, 3, 4, 15},
+		{8, 7, 6, 32},
+		{10, 0, 1, 1},
+		{10, 1, 0, 6},
+		{10, 9, 8, 50},
+	}
+	for _, test := range tests {
+		n, ok := SquareNumber(test.size, test.row, test.col)
+		if !ok || n != test.n {
+			t.Errorf("(%d, %d) on %dx%d: wanted square %d, got %d (ok %v)", test.row, test.col, test.size, test.size, test.n, n, ok)
+		}
+		row, col, ok := SquareCoord(test.size, test.n)
+		if !ok || row != test.row || col != test.col {
+			t.Errorf("square %d on %dx%d: wanted (%d, %d), got (%d, %d) (ok %v)", test.n, test.size, test.size, test.row, test.col, row, col, ok)
+		}
+	}
+}
+
+func TestInvalidSquares(t *testing.T) {
+	if _, ok := SquareNumber(8, 0, 0); ok {
+		t.Errorf("(0, 0) is a white tile and has no number")
+	}
+	if _, ok := SquareNumber(8, 8, 1); ok {
+		t.Errorf("(8, 1) is out of a 8x8 board")
+	}
+	for _, n := range []int{0, -1, 33} {
+		if _, _, ok := SquareCoord(8, n); ok {
+			t.Errorf("square %d shouldn't exist on a 8x8 board", n)
+		}
+	}
+	if _, _, ok := SquareCoord(10, 50); !ok {
+		t.Errorf("square 50 should exist on a 10x10 board")
+	}
+}
+
+func TestSquaresCoverBoard(t *testing.T) {
+	for _, size := range []byte{8, 10} {
+		b := NewBoard(size)
+		for n := 1; n <= SquareCount(size); n++ {
+			row, col, ok := SquareCoord(size, n)
+			if !ok || TileColor(row, col) != BlackColor || b.IsOccupied(row, col) {
+				t.Fatalf("square %d maps to an invalid or repeated tile (%d, %d)", n, row, col)
+			}
+			b.Set(row, col, WhiteColor, PawnKind)
+		}
+	}
+}
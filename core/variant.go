@@ -0,0 +1,122 @@
+package core
+
+// A Variant is the set of rules a game of checkers is played by.
+// The predefined variants below cover the most common rule sets,
+// and custom ones can be made by copying and changing one of them.
+type Variant struct {
+	Name string
+
+	// Number of rows and columns of the board, DefaultBoardSize if zero
+	Size byte
+
+	CaptureRule
+	BestRule
+
+	// Among the captures allowed by the BestRule, you must capture with a king rather than with a pawn,
+	// then capture the most kings, then capture a king as early as possible in the sequence (Italian rules)
+	QualityPriority bool
+
+	// Kings move and capture any distance along a diagonal, instead of a single tile
+	FlyingKings bool
+
+	// Pawns can capture backwards, not only forward
+	PawnsCaptureBackward bool
+
+	// Pawns can capture kings (false in Italian rules)
+	PawnsCaptureKings bool
+
+	// A pawn that reaches the crowning row in the middle of a capture sequence
+	// is crowned right away and keeps capturing as a king (Russian rules).
+	// Otherwise it's only crowned if the sequence ends there.
+	CrownMidCapture bool
+
+	// Turns (plies) without captures and without pawn moves until the game is a draw, 0 to disable
+	StagnantTurnsToDraw int16
+
+	// Turns (plies) in a special ending (see inSpecialEnding) until the game is a draw, 0 to disable
+	SpecialEndingTurnsToDraw int16
+
+	// Times the same position (with the same player to move) has to occur for the game to be a draw, 0 to disable
+	RepetitionsToDraw int16
+}
+
+var AmericanVariant = Variant{
+	Name:                "american",
+	Size:                8,
+	CaptureRule:         CapturesMandatory,
+	BestRule:            BestNotMandatory,
+	PawnsCaptureKings:   true,
+	StagnantTurnsToDraw: 80,
+	RepetitionsToDraw:   3,
+}
+
+var BrazilianVariant = Variant{
+	Name:                     "brazilian",
+	Size:                     8,
+	CaptureRule:              CapturesMandatory,
+	BestRule:                 BestMandatory,
+	FlyingKings:              true,
+	PawnsCaptureBackward:     true,
+	PawnsCaptureKings:        true,
+	StagnantTurnsToDraw:      20,
+	SpecialEndingTurnsToDraw: 5,
+	RepetitionsToDraw:        3,
+}
+
+var RussianVariant = Variant{
+	Name:                 "russian",
+	Size:                 8,
+	CaptureRule:          CapturesMandatory,
+	BestRule:             BestNotMandatory,
+	FlyingKings:          true,
+	PawnsCaptureBackward: true,
+	PawnsCaptureKings:    true,
+	CrownMidCapture:      true,
+	StagnantTurnsToDraw:  30,
+	RepetitionsToDraw:    3,
+}
+
+// Same rules as the Brazilian variant, but on a 10x10 board.
+// The counters for specific endings (e.g. 3 kings vs 1 king) are not implemented.
+var InternationalVariant = Variant{
+	Name:                 "international",
+	Size:                 10,
+	CaptureRule:          CapturesMandatory,
+	BestRule:             BestMandatory,
+	FlyingKings:          true,
+	PawnsCaptureBackward: true,
+	PawnsCaptureKings:    true,
+	StagnantTurnsToDraw:  50,
+	RepetitionsToDraw:    3,
+}
+
+var ItalianVariant = Variant{
+	Name:                "italian",
+	Size:                8,
+	CaptureRule:         CapturesMandatory,
+	BestRule:            BestMandatory,
+	QualityPriority:     true,
+	StagnantTurnsToDraw: 80,
+	RepetitionsToDraw:   3,
+}
+
+var Variants = []Variant{
+	AmericanVariant,
+	BrazilianVariant,
+	InternationalVariant,
+	RussianVariant,
+	ItalianVariant,
+}
+
+func VariantFromName(name string) (Variant, bool) {
+	for _, v := range Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+func (v Variant) String() string {
+	return v.Name
+}
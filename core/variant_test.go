@@ -0,0 +1,206 @@
+package core
+
+import "testing"
+
+func TestVariantFromName(t *testing.T) {
+	for _, v := range Variants {
+		got, ok := VariantFromName(v.Name)
+		if !ok || got != v {
+			t.Errorf("expected to find variant %v", v)
+		}
+		if v.String() != v.Name {
+			t.Errorf("expected variant string to be its name %q, got %q", v.Name, v.String())
+		}
+	}
+	if _, ok := VariantFromName("klingon"); ok {
+		t.Errorf("expected not to find a variant that doesn't exist")
+	}
+}
+
+func TestNonFlyingKings(t *testing.T) {
+	b := new(Board)
+	b.Set(3, 3, WhiteColor, KingKind)
+	b.Set(4, 4, BlackColor, PawnKind)
+	b.Set(1, 1, BlackColor, PawnKind)
+
+	t.Log("\n" + b.String())
+
+	pliesWant := []Ply{
+		{
+			MakeMoveInstruction(3, 3, 5, 5),
+			MakeCaptureInstruction(4, 4, BlackColor, PawnKind),
+		},
+	}
+	pliesGot := GeneratePlies(nil, b, WhiteColor, AmericanVariant)
+	assertEqualPlies(t, pliesGot, pliesWant)
+
+	// the pawn at (1, 1) is too far away to be captured
+	b.Clear(4, 4)
+	pliesWant = []Ply{
+		{MakeMoveInstruction(3, 3, 2, 2)},
+		{MakeMoveInstruction(3, 3, 2, 4)},
+		{MakeMoveInstruction(3, 3, 4, 2)},
+		{MakeMoveInstruction(3, 3, 4, 4)},
+	}
+	pliesGot = GeneratePlies(nil, b, WhiteColor, AmericanVariant)
+	assertEqualPlies(t, pliesGot, pliesWant)
+}
+
+func TestPawnsCaptureBackward(t *testing.T) {
+	b := new(Board)
+	b.Set(3, 3, WhiteColor, PawnKind)
+	b.Set(4, 4, BlackColor, PawnKind)
+
+	t.Log("\n" + b.String())
+
+	backwardCapture := Ply{
+		MakeMoveInstruction(3, 3, 5, 5),
+		MakeCaptureInstruction(4, 4, BlackColor, PawnKind),
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, BrazilianVariant), []Ply{backwardCapture})
+
+	simplePlies := []Ply{
+		{MakeMoveInstruction(3, 3, 2, 2)},
+		{MakeMoveInstruction(3, 3, 2, 4)},
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, AmericanVariant), simplePlies)
+}
+
+func TestPawnsCaptureKings(t *testing.T) {
+	b := new(Board)
+	b.Set(5, 2, WhiteColor, PawnKind)
+	b.Set(4, 3, BlackColor, KingKind)
+
+	t.Log("\n" + b.String())
+
+	capture := Ply{
+		MakeMoveInstruction(5, 2, 3, 4),
+		MakeCaptureInstruction(4, 3, BlackColor, KingKind),
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, AmericanVariant), []Ply{capture})
+
+	simplePlies := []Ply{
+		{MakeMoveInstruction(5, 2, 4, 1)},
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, ItalianVariant), simplePlies)
+}
+
+func TestCrownMidCapture(t *testing.T) {
+	b := new(Board)
+	b.Set(2, 1, WhiteColor, PawnKind)
+	b.Set(1, 2, BlackColor, PawnKind)
+	b.Set(2, 5, BlackColor, PawnKind)
+
+	t.Log("\n" + b.String())
+
+	pliesWant := []Ply{
+		{
+			MakeMoveInstruction(2, 1, 0, 3),
+			MakeCaptureInstruction(1, 2, BlackColor, PawnKind),
+			MakeCrownInstruction(0, 3),
+		},
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, BrazilianVariant), pliesWant)
+
+	pliesWant = []Ply{
+		{
+			MakeMoveInstruction(2, 1, 0, 3),
+			MakeCaptureInstruction(1, 2, BlackColor, PawnKind),
+			MakeCrownInstruction(0, 3),
+			MakeMoveInstruction(0, 3, 3, 6),
+			MakeCaptureInstruction(2, 5, BlackColor, PawnKind),
+		},
+		{
+			MakeMoveInstruction(2, 1, 0, 3),
+			MakeCaptureInstruction(1, 2, BlackColor, PawnKind),
+			MakeCrownInstruction(0, 3),
+			MakeMoveInstruction(0, 3, 4, 7),
+			MakeCaptureInstruction(2, 5, BlackColor, PawnKind),
+		},
+	}
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, RussianVariant), pliesWant)
+
+	// the board is left as it was
+	want := new(Board)
+	want.Set(2, 1, WhiteColor, PawnKind)
+	want.Set(1, 2, BlackColor, PawnKind)
+	want.Set(2, 5, BlackColor, PawnKind)
+	assertEqualBoards(t, b, want)
+
+	g := NewCustomGame(RussianVariant, b, WhiteColor)
+	undo, err := g.DoPly(pliesWant[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, k := g.Board().Get(3, 6); !g.Board().IsOccupied(3, 6) || c != WhiteColor || k != KingKind {
+		t.Errorf("expected a white king at (3, 6) after the capture")
+	}
+	g.UndoPly(undo)
+	assertEqualBoards(t, g.Board(), want)
+}
+
+func TestQualityPriority(t *testing.T) {
+	b := new(Board)
+	// capturing with a king
+	b.Set(4, 4, WhiteColor, KingKind)
+	b.Set(3, 3, BlackColor, PawnKind)
+	b.Set(1, 1, BlackColor, KingKind)
+	b.Set(3, 5, BlackColor, KingKind)
+	b.Set(1, 5, BlackColor, PawnKind)
+	// capturing with a pawn
+	b.Set(6, 1, WhiteColor, PawnKind)
+	b.Set(5, 2, BlackColor, PawnKind)
+	b.Set(3, 2, BlackColor, PawnKind)
+
+	t.Log("\n" + b.String())
+
+	pawnThenKing := Ply{
+		MakeMoveInstruction(4, 4, 2, 2),
+		MakeCaptureInstruction(3, 3, BlackColor, PawnKind),
+		MakeMoveInstruction(2, 2, 0, 0),
+		MakeCaptureInstruction(1, 1, BlackColor, KingKind),
+	}
+	kingThenPawn := Ply{
+		MakeMoveInstruction(4, 4, 2, 6),
+		MakeCaptureInstruction(3, 5, BlackColor, KingKind),
+		MakeMoveInstruction(2, 6, 0, 4),
+		MakeCaptureInstruction(1, 5, BlackColor, PawnKind),
+	}
+	withPawn := Ply{
+		MakeMoveInstruction(6, 1, 4, 3),
+		MakeCaptureInstruction(5, 2, BlackColor, PawnKind),
+		MakeMoveInstruction(4, 3, 2, 1),
+		MakeCaptureInstruction(3, 2, BlackColor, PawnKind),
+	}
+
+	v := ItalianVariant
+	v.QualityPriority = false
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, v), []Ply{pawnThenKing, kingThenPawn, withPawn})
+
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, ItalianVariant), []Ply{kingThenPawn})
+
+	// the majority rule still comes first: capturing a king first
+	// doesn't matter when that sequence captures fewer pieces
+	b.Clear(1, 5)
+	t.Log("\n" + b.String())
+	assertEqualPlies(t, GeneratePlies(nil, b, WhiteColor, ItalianVariant), []Ply{pawnThenKing})
+}
+
+func TestDrawRulesDisabled(t *testing.T) {
+	b := DecodeBoard(`
+	  ..@
+		.
+		.#
+	`)
+	v := BrazilianVariant
+	v.SpecialEndingTurnsToDraw = 0
+	v.StagnantTurnsToDraw = 0
+	v.RepetitionsToDraw = 0
+	g := NewCustomGame(v, b, WhiteColor)
+	for i := 0; i < 30; i++ {
+		if _, err := g.DoPly(randomInoffensiveMove(g.Board(), g.ToPlay())); err != nil {
+			t.Fatal(err)
+		}
+		assertGameResult(t, g, PlayingResult)
+	}
+}
@@ -0,0 +1,34 @@
+package core
+
+// Zobrist hashing: each (tile, color, kind) gets a random key, and the hash of a board
+// is the xor of the keys of its pieces, so it can be updated incrementally as pieces
+// are set, cleared, crowned and uncrowned. The keys come from a fixed seed so that
+// hashes are the same across runs (they can be stored, e.g. in an opening book).
+
+var zobristPieceKeys [MaxBoardSize * MaxBoardSize][4]uint64
+
+// Xor'ed into the hash of a game when it's black's turn
+var zobristBlackToPlayKey uint64
+
+// splitmix64
+func zobristNext(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func init() {
+	state := uint64(0x636865636b657273)
+	for n := range zobristPieceKeys {
+		for i := range zobristPieceKeys[n] {
+			zobristPieceKeys[n][i] = zobristNext(&state)
+		}
+	}
+	zobristBlackToPlayKey = zobristNext(&state)
+}
+
+func zobristPieceKey(n uint, c Color, k Kind) uint64 {
+	return zobristPieceKeys[n][byte(c)<<1|byte(k)]
+}
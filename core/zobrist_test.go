@@ -0,0 +1,92 @@
+package core
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Hash of the board computed from scratch
+func rehash(b *Board) uint64 {
+	var hash uint64
+	size := b.Size()
+	for row := byte(0); row < size; row++ {
+		for col := byte(0); col < size; col++ {
+			if b.IsOccupied(row, col) {
+				c, k := b.Get(row, col)
+				hash ^= zobristPieceKey(b.index(row, col), c, k)
+			}
+		}
+	}
+	return hash
+}
+
+func TestHashIncremental(t *testing.T) {
+	for _, v := range Variants {
+		g := NewCustomGame(v, nil, WhiteColor)
+		var undos []*UndoInfo
+		var hashes []uint64
+		for i := 0; i < 100 && !g.Result().Over(); i++ {
+			hashes = append(hashes, g.Hash())
+			ps := g.Plies()
+			undo, err := g.DoPly(ps[rand.Intn(len(ps))])
+			if err != nil {
+				t.Fatal(err)
+			}
+			undos = append(undos, undo)
+			if g.Board().Hash() != rehash(g.Board()) {
+				t.Fatalf("%v: incremental hash differs from the hash computed from scratch\n%v", v, g.Board())
+			}
+		}
+		for i := len(undos) - 1; i >= 0; i-- {
+			g.UndoPly(undos[i])
+			if g.Hash() != hashes[i] {
+				t.Fatalf("%v: wrong hash after undoing ply %d", v, i)
+			}
+		}
+	}
+}
+
+func TestHashPrimitives(t *testing.T) {
+	b := NewBoard(8)
+	if b.Hash() != 0 {
+		t.Errorf("expected empty board to have hash 0")
+	}
+	b.Set(2, 3, WhiteColor, PawnKind)
+	pawn := b.Hash()
+	b.Crown(2, 3)
+	if b.Hash() == pawn || b.Hash() != rehash(b) {
+		t.Errorf("wrong hash after crowning")
+	}
+	b.Crown(2, 3)
+	if b.Hash() != rehash(b) {
+		t.Errorf("crowning a king should keep the hash")
+	}
+	b.Uncrown(2, 3)
+	if b.Hash() != pawn {
+		t.Errorf("wrong hash after uncrowning")
+	}
+	b.Set(2, 3, BlackColor, KingKind)
+	if b.Hash() != rehash(b) {
+		t.Errorf("wrong hash after overwriting a piece")
+	}
+	b.Clear(2, 3)
+	b.Clear(2, 3)
+	b.Crown(4, 5)
+	if b.Hash() != 0 {
+		t.Errorf("expected hash 0 after clearing the board")
+	}
+	if b.Copy().Hash() != b.Hash() {
+		t.Errorf("copies should have the same hash")
+	}
+}
+
+func TestHashPlayerToMove(t *testing.T) {
+	white := NewCustomGame(BrazilianVariant, nil, WhiteColor)
+	black := NewCustomGame(BrazilianVariant, nil, BlackColor)
+	if white.Hash() == black.Hash() {
+		t.Errorf("expected the player to move to change the hash")
+	}
+	if white.Board().Hash() != black.Board().Hash() {
+		t.Errorf("expected equal boards to have equal hashes")
+	}
+}
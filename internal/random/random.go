@@ -0,0 +1,27 @@
+// Package random lets the searchers take an optional *rand.Rand,
+// using the global source of math/rand when they aren't given one.
+package random
+
+import "math/rand"
+
+// The methods of rand.Rand the searchers use
+type Source interface {
+	Float64() float64
+	NormFloat64() float64
+	Intn(n int) int
+}
+
+// The top-level functions of math/rand, which are safe for concurrent use
+type global struct{}
+
+func (global) Float64() float64     { return rand.Float64() }
+func (global) NormFloat64() float64 { return rand.NormFloat64() }
+func (global) Intn(n int) int       { return rand.Intn(n) }
+
+// Returns r, or the global source if r is nil
+func Or(r *rand.Rand) Source {
+	if r == nil {
+		return global{}
+	}
+	return r
+}
@@ -0,0 +1,27 @@
+package random
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOr(t *testing.T) {
+	if _, ok := Or(nil).(global); !ok {
+		t.Errorf("expected the global source for a nil rand")
+	}
+	r := rand.New(rand.NewSource(1))
+	if Or(r) != Source(r) {
+		t.Errorf("expected the given rand")
+	}
+
+	src := Or(nil)
+	for i := 0; i < 100; i++ {
+		if f := src.Float64(); f < 0 || f >= 1 {
+			t.Errorf("expected a float in [0, 1), got %v", f)
+		}
+		if n := src.Intn(3); n < 0 || n >= 3 {
+			t.Errorf("expected an int in [0, 3), got %v", n)
+		}
+		src.NormFloat64()
+	}
+}
@@ -0,0 +1,256 @@
+// Package mcts searches for plies with Monte Carlo tree search (UCT): instead of
+// going through the whole game tree up to a depth, it grows the tree towards the
+// plies that won the most random games (playouts) played from them.
+package mcts
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/internal/random"
+	"github.com/luc527/go_checkers/minimax"
+)
+
+const (
+	DefaultIterations   = 2000
+	DefaultPlayoutDepth = 80
+	DefaultEpsilon      = 0.25
+)
+
+// A Searcher is a minimax.Searcher that uses Monte Carlo tree search.
+// It stops after the number of Iterations, or when the TimeLimit has elapsed,
+// whichever comes first. With neither, it does DefaultIterations.
+type Searcher struct {
+	// Zero means no limit
+	Iterations int
+	// Zero means no limit, otherwise kept between minimax.MinTimeLimit and minimax.MaxTimeLimit
+	TimeLimit time.Duration
+	// Exploration constant of UCT, the higher the more the plies with few playouts are tried.
+	// math.Sqrt2 if zero.
+	Exploration float64
+	// Optional. With a heuristic, playouts play the ply with the best heuristic value for the
+	// player to move, except for a chance of Epsilon of playing a random one (DefaultEpsilon if zero),
+	// and playouts cut at PlayoutDepth are scored by it. Otherwise they're random, and cut ones are draws.
+	Heuristic minimax.Heuristic
+	Epsilon   float64
+	// Maximum plies of each playout, DefaultPlayoutDepth if zero
+	PlayoutDepth int
+	// Picks the plies expanded and the plies of the playouts, the global source of math/rand if nil.
+	// Searches sharing one can't run at the same time.
+	Rand *rand.Rand
+}
+
+var _ minimax.Searcher = Searcher{}
+
+type node struct {
+	parent *node
+	// ply that leads to the node from its parent
+	ply c.Ply
+	// player who played the ply, the values are from their point of view
+	mover    c.Color
+	children []*node
+	// plies not expanded into children yet
+	untried []c.Ply
+	visits  int
+	// sum of the scores of the playouts through the node
+	value float64
+}
+
+func newNode(parent *node, ply c.Ply, g *c.Game) *node {
+	n := &node{
+		parent: parent,
+		ply:    ply,
+		mover:  g.ToPlay().Opposite(),
+	}
+	if !g.Result().Over() {
+		n.untried = c.CopyPlies(g.Plies())
+	}
+	return n
+}
+
+func (n *node) uct(exploration float64) float64 {
+	return n.value/float64(n.visits) + exploration*math.Sqrt(math.Log(float64(n.parent.visits))/float64(n.visits))
+}
+
+func (s Searcher) Search(g *c.Game) c.Ply {
+	return s.SearchContext(context.Background(), g)
+}
+
+func (s Searcher) iterations() int {
+	if s.Iterations == 0 && s.TimeLimit == 0 {
+		return DefaultIterations
+	}
+	return s.Iterations
+}
+
+func (s Searcher) timeLimit() time.Duration {
+	if s.TimeLimit == 0 {
+		return 0
+	}
+	if s.TimeLimit < minimax.MinTimeLimit {
+		return minimax.MinTimeLimit
+	}
+	if s.TimeLimit > minimax.MaxTimeLimit {
+		return minimax.MaxTimeLimit
+	}
+	return s.TimeLimit
+}
+
+// Returns the ply with the most playouts, nil if the game is over or the context
+// is done before the first playout. As in minimax.TimeLimitedSearcher, the time
+// limit is one more deadline for the context.
+func (s Searcher) SearchContext(ctx context.Context, g *c.Game) c.Ply {
+	if g.Result().Over() {
+		return nil
+	}
+	if plies := g.Plies(); len(plies) == 1 {
+		return plies[0]
+	}
+	if tlim := s.timeLimit(); tlim > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tlim)
+		defer cancel()
+	}
+
+	t := tree{Searcher: s, r: random.Or(s.Rand)}
+	if t.Exploration == 0 {
+		t.Exploration = math.Sqrt2
+	}
+	if t.Epsilon == 0 {
+		t.Epsilon = DefaultEpsilon
+	}
+	if t.PlayoutDepth == 0 {
+		t.PlayoutDepth = DefaultPlayoutDepth
+	}
+
+	g = g.Copy()
+	root := newNode(nil, nil, g)
+	iterations := s.iterations()
+	for i := 0; iterations == 0 || i < iterations; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		t.iterate(root, g)
+	}
+
+	best := root.children
+	if len(best) == 0 {
+		return nil
+	}
+	ply, visits := best[0].ply, best[0].visits
+	for _, n := range best[1:] {
+		if n.visits > visits {
+			ply, visits = n.ply, n.visits
+		}
+	}
+	return ply
+}
+
+// The options of the search with the defaults applied
+type tree struct {
+	Searcher
+	r random.Source
+}
+
+// Selection, expansion, playout and backpropagation.
+// The game is at the root before and after.
+func (t tree) iterate(root *node, g *c.Game) {
+	var undos []*c.UndoInfo
+
+	n := root
+	for len(n.untried) == 0 && len(n.children) > 0 {
+		best := n.children[0]
+		bestValue := best.uct(t.Exploration)
+		for _, child := range n.children[1:] {
+			if v := child.uct(t.Exploration); v > bestValue {
+				best, bestValue = child, v
+			}
+		}
+		n = best
+		undo, _ := g.DoPly(n.ply)
+		undos = append(undos, undo)
+	}
+
+	if len(n.untried) > 0 {
+		i := t.r.Intn(len(n.untried))
+		ply := n.untried[i]
+		n.untried[i] = n.untried[len(n.untried)-1]
+		n.untried = n.untried[:len(n.untried)-1]
+
+		undo, _ := g.DoPly(ply)
+		undos = append(undos, undo)
+		child := newNode(n, ply, g)
+		n.children = append(n.children, child)
+		n = child
+	}
+
+	score := t.playout(g)
+	for ; n != nil; n = n.parent {
+		n.visits++
+		if n.mover == c.WhiteColor {
+			n.value += score
+		} else {
+			n.value += 1 - score
+		}
+	}
+
+	for i := len(undos) - 1; i >= 0; i-- {
+		g.UndoPly(undos[i])
+	}
+}
+
+// Plays the game until it ends or the playout depth, and returns the score for white:
+// 1 for a win, 0 for a loss and 0.5 for a draw, or in between according to the heuristic.
+// The game is left as it was.
+func (t tree) playout(g *c.Game) float64 {
+	var undos []*c.UndoInfo
+	defer func() {
+		for i := len(undos) - 1; i >= 0; i-- {
+			g.UndoPly(undos[i])
+		}
+	}()
+
+	for depth := 0; ; depth++ {
+		switch res := g.Result(); {
+		case res == c.WhiteWonResult:
+			return 1
+		case res == c.BlackWonResult:
+			return 0
+		case res == c.DrawResult:
+			return 0.5
+		}
+		if depth >= t.PlayoutDepth {
+			if t.Heuristic == nil {
+				return 0.5
+			}
+			return 1 / (1 + math.Exp(-t.Heuristic(g.Board(), c.WhiteColor)))
+		}
+		undo, _ := g.DoPly(t.playoutPly(g))
+		undos = append(undos, undo)
+	}
+}
+
+func (t tree) playoutPly(g *c.Game) c.Ply {
+	plies := g.Plies()
+	if t.Heuristic == nil || len(plies) == 1 || t.r.Float64() < t.Epsilon {
+		return plies[t.r.Intn(len(plies))]
+	}
+	player := g.ToPlay()
+	var best []c.Ply
+	bestValue := math.Inf(-1)
+	for _, p := range plies {
+		undo, _ := g.DoPly(p)
+		v := t.Heuristic(g.Board(), player)
+		g.UndoPly(undo)
+		if v > bestValue {
+			best, bestValue = best[:0], v
+		}
+		if v == bestValue {
+			best = append(best, p)
+		}
+	}
+	return best[t.r.Intn(len(best))]
+}
@@ -0,0 +1,149 @@
+package mcts
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+)
+
+func assertLegalPly(t *testing.T, g *c.Game, ply c.Ply) {
+	t.Helper()
+	for _, p := range g.Plies() {
+		if p.Equals(ply) {
+			return
+		}
+	}
+	t.Errorf("searcher returned a ply that is not legal: %v", ply)
+}
+
+// White must capture with the king. It wins by landing next to its pawn, leaving black
+// with no moves. Landing elsewhere, black captures the pawn and then the king.
+const captureBoard = `
+.......@
+........
+.....#..
+........
+........
+........
+.o......
+#.......
+`
+
+func TestSearchFindsWin(t *testing.T) {
+	searchers := []Searcher{
+		{Iterations: 500, Rand: rand.New(rand.NewSource(1))},
+		{Iterations: 500, Heuristic: minimax.WeightedCountHeuristic, Rand: rand.New(rand.NewSource(1))},
+	}
+	for _, s := range searchers {
+		g := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(captureBoard), c.WhiteColor)
+		before := g.Copy()
+		ply := s.Search(g)
+		if !g.Equals(before) {
+			t.Errorf("the search changed the game")
+		}
+		assertLegalPly(t, g, ply)
+		g.DoPly(ply)
+		if g.Result() != c.WhiteWonResult {
+			t.Errorf("expected the winning ply, got %v", ply)
+		}
+	}
+}
+
+func TestSearchPlaysGame(t *testing.T) {
+	s := Searcher{
+		Iterations:   50,
+		PlayoutDepth: 20,
+		Heuristic:    minimax.WeightedCountHeuristic,
+		Epsilon:      0.5,
+		Exploration:  1,
+	}
+	g := c.NewGame()
+	for i := 0; i < 20 && !g.Result().Over(); i++ {
+		ply := s.Search(g)
+		assertLegalPly(t, g, ply)
+		g.DoPly(ply)
+	}
+}
+
+func TestSearchDeterministic(t *testing.T) {
+	g := c.NewGame()
+	a := Searcher{Iterations: 200, Rand: rand.New(rand.NewSource(42))}.Search(g)
+	b := Searcher{Iterations: 200, Rand: rand.New(rand.NewSource(42))}.Search(g)
+	if !a.Equals(b) {
+		t.Errorf("searches with the same seed returned %v and %v", a, b)
+	}
+}
+
+func TestSearchGameOver(t *testing.T) {
+	g := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(".\n.\n.\n...o"), c.BlackColor)
+	if ply := (Searcher{}).Search(g); ply != nil {
+		t.Errorf("expected no ply in a finished game, got %v", ply)
+	}
+}
+
+func TestSearchSinglePly(t *testing.T) {
+	// the capture is mandatory
+	g := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(`
+		........
+		........
+		..x.....
+		...o....
+	`), c.WhiteColor)
+	if len(g.Plies()) != 1 {
+		t.Fatalf("expected a single ply, got %v", g.Plies())
+	}
+	ply := Searcher{TimeLimit: minimax.MaxTimeLimit}.Search(g)
+	if !ply.Equals(g.Plies()[0]) {
+		t.Errorf("expected the only ply %v, got %v", g.Plies()[0], ply)
+	}
+}
+
+func TestSearchTimeLimit(t *testing.T) {
+	g := c.NewGame()
+	start := time.Now()
+	// below the minimum, so the limit is minimax.MinTimeLimit
+	ply := Searcher{TimeLimit: time.Millisecond}.Search(g)
+	elapsed := time.Since(start)
+	if elapsed < minimax.MinTimeLimit || elapsed > 3*minimax.MinTimeLimit {
+		t.Errorf("expected the search to take about %v, took %v", minimax.MinTimeLimit, elapsed)
+	}
+	assertLegalPly(t, g, ply)
+
+	if tlim := (Searcher{TimeLimit: time.Hour}).timeLimit(); tlim != minimax.MaxTimeLimit {
+		t.Errorf("expected the time limit to be kept at %v, got %v", minimax.MaxTimeLimit, tlim)
+	}
+}
+
+func TestSearchContextCancel(t *testing.T) {
+	g := c.NewGame()
+	s := Searcher{TimeLimit: minimax.MaxTimeLimit}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	ply := s.SearchContext(ctx, g)
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("expected the context deadline to come before the time limit, took %v", elapsed)
+	}
+	assertLegalPly(t, g, ply)
+
+	// cancelled before even one iteration
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if ply := s.SearchContext(ctx, g); ply != nil {
+		t.Errorf("expected no ply from a search cancelled before any playout, got %v", ply)
+	}
+}
+
+func TestDefaultIterations(t *testing.T) {
+	if n := (Searcher{}).iterations(); n != DefaultIterations {
+		t.Errorf("expected %d iterations by default, got %d", DefaultIterations, n)
+	}
+	if n := (Searcher{TimeLimit: time.Second}).iterations(); n != 0 {
+		t.Errorf("expected no iteration limit with a time limit, got %d", n)
+	}
+}
@@ -0,0 +1,142 @@
+package minimax
+
+import (
+	"context"
+	"math/rand"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/internal/random"
+)
+
+const (
+	MinLevel = 1
+	MaxLevel = 10
+)
+
+// How a LevelSearcher plays at a difficulty level. The values of the plies are
+// in pawns, the unit of WeightedCountHeuristic.
+type Level struct {
+	// Depth of the search of the plies
+	Depth int
+	// Standard deviation of the noise added to the value of each ply, so the
+	// searcher misjudges positions instead of only failing to see far ahead
+	Noise float64
+	// The ply played is picked at random among the ones whose value (with noise)
+	// is within Margin of the best one
+	Margin float64
+	// Chance of overlooking the bigger captures, playing among the plies that capture the fewest
+	// pieces instead. Only has an effect when the BestRule isn't mandatory, e.g. in the American
+	// variant, where a capture that takes fewer pieces can be chosen over one that takes more.
+	MissedCapture float64
+	// Strength as an Elo rating, relative to the other levels
+	Rating int
+}
+
+// The levels from MinLevel to MaxLevel. The ratings were measured with WeightedCountHeuristic,
+// playing each level against the one below (see testdata/levels.txt), starting from 800.
+var Levels = [MaxLevel]Level{
+	{Depth: 1, Noise: 1.5, Margin: 1, MissedCapture: 0.3, Rating: 800},
+	{Depth: 1, Noise: 1, Margin: 0.7, MissedCapture: 0.25, Rating: 940},
+	{Depth: 2, Noise: 0.8, Margin: 0.5, MissedCapture: 0.2, Rating: 1110},
+	{Depth: 2, Noise: 0.6, Margin: 0.35, MissedCapture: 0.15, Rating: 1210},
+	{Depth: 3, Noise: 0.45, Margin: 0.25, MissedCapture: 0.1, Rating: 1400},
+	{Depth: 3, Noise: 0.3, Margin: 0.15, MissedCapture: 0.07, Rating: 1490},
+	{Depth: 4, Noise: 0.2, Margin: 0.1, MissedCapture: 0.04, Rating: 1630},
+	{Depth: 5, Noise: 0.1, Margin: 0.05, MissedCapture: 0.02, Rating: 1750},
+	{Depth: 6, Noise: 0.05, Margin: 0.02, MissedCapture: 0.01, Rating: 1880},
+	{Depth: 7, Rating: 2010},
+}
+
+// A LevelSearcher is a Searcher that plays at a difficulty level, making mistakes
+// the way a person would: it searches every ply shallowly, misjudges their values
+// by some noise, and picks one of the plies that look about as good as the best.
+// Forced wins and losses it finds are never misjudged.
+type LevelSearcher struct {
+	// From MinLevel to MaxLevel, kept within them
+	Level int
+	// WeightedCountHeuristic if nil
+	Heuristic
+	// Draws the noise, the missed captures and the pick among the best plies,
+	// the global source of math/rand if nil. Seeded, it makes the games repeatable.
+	Rand *rand.Rand
+}
+
+var _ Searcher = LevelSearcher{}
+
+func (s LevelSearcher) level() Level {
+	if s.Level < MinLevel {
+		return Levels[0]
+	}
+	if s.Level > MaxLevel {
+		return Levels[MaxLevel-1]
+	}
+	return Levels[s.Level-1]
+}
+
+func (s LevelSearcher) Search(g *c.Game) c.Ply {
+	return s.SearchContext(context.Background(), g)
+}
+
+// Returns nil if the context is done by the time every ply is searched
+func (s LevelSearcher) SearchContext(ctx context.Context, g *c.Game) c.Ply {
+	if g.Result().Over() {
+		return nil
+	}
+	plies := g.Plies()
+	if len(plies) == 1 {
+		return plies[0]
+	}
+
+	level := s.level()
+	h := s.Heuristic
+	if h == nil {
+		h = WeightedCountHeuristic
+	}
+	searcher := DepthLimitedSearcher{ToMax: g.ToPlay(), Heuristic: h, DepthLimit: level.Depth}
+	results := searcher.AnalyzeMultiPV(ctx, g, len(plies))
+	// a cancelled analysis may leave out some plies, maybe the best ones
+	if ctx.Err() != nil || len(results) == 0 {
+		return nil
+	}
+
+	src := random.Or(s.Rand)
+	if src.Float64() < level.MissedCapture {
+		results = fewestCaptures(results)
+	}
+
+	values := make([]float64, len(results))
+	best := 0
+	for i, r := range results {
+		values[i] = r.Value
+		if r.MateIn == 0 {
+			values[i] += src.NormFloat64() * level.Noise
+		}
+		if values[i] > values[best] {
+			best = i
+		}
+	}
+	var candidates []c.Ply
+	for i, r := range results {
+		if values[i] >= values[best]-level.Margin {
+			candidates = append(candidates, r.Ply)
+		}
+	}
+	return candidates[src.Intn(len(candidates))]
+}
+
+// The results of the plies that capture the fewest pieces
+func fewestCaptures(results []SearchResult) []SearchResult {
+	fewest := captureCount(results[0].Ply)
+	for _, r := range results[1:] {
+		if n := captureCount(r.Ply); n < fewest {
+			fewest = n
+		}
+	}
+	var filtered []SearchResult
+	for _, r := range results {
+		if captureCount(r.Ply) == fewest {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,189 @@
+package minimax
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func TestLevelSearcherLevels(t *testing.T) {
+	for level := MinLevel - 1; level <= MaxLevel+1; level++ {
+		s := LevelSearcher{Level: level, Rand: rand.New(rand.NewSource(1))}
+		want := level
+		if want < MinLevel {
+			want = MinLevel
+		} else if want > MaxLevel {
+			want = MaxLevel
+		}
+		if s.level() != Levels[want-1] {
+			t.Errorf("level %d: expected the searcher to play at level %d", level, want)
+		}
+		g := c.NewGame()
+		assertLegalPly(t, g, s.Search(g))
+	}
+
+	for i := 1; i < len(Levels); i++ {
+		if Levels[i].Rating <= Levels[i-1].Rating || Levels[i].Depth < Levels[i-1].Depth {
+			t.Errorf("level %d should be stronger than level %d", i+1, i)
+		}
+	}
+}
+
+// The ratings should differ by what was measured, within its margin of error
+func TestLevelRatings(t *testing.T) {
+	f, err := os.Open("testdata/levels.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	measured := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "L") {
+			continue
+		}
+		var level, below, games, wins, draws, losses int
+		var points, elo, margin float64
+		_, err := fmt.Sscanf(line, "L%d vs L%d %d +%d =%d -%d %g%% %g ± %g", &level, &below, &games, &wins, &draws, &losses, &points, &elo, &margin)
+		if err != nil || below != level-1 || level <= MinLevel || level > MaxLevel {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		diff := float64(Levels[level-1].Rating - Levels[below-1].Rating)
+		if math.Abs(diff-elo) > margin {
+			t.Errorf("level %d is rated %v above level %d, measured %+v ± %v", level, diff, below, elo, margin)
+		}
+		measured++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if measured != MaxLevel-MinLevel {
+		t.Errorf("expected every level to be measured against the one below, got %d", measured)
+	}
+}
+
+func TestLevelSearcherFindsWin(t *testing.T) {
+	// White must capture with the king, and only wins by landing next to its pawn.
+	// Landing elsewhere, black captures the pawn and then the king.
+	b := c.DecodeBoard(`
+		.......@
+		........
+		.....#..
+		........
+		........
+		........
+		.o......
+		#.......
+	`)
+	for level := MinLevel; level <= MaxLevel; level++ {
+		g := c.NewCustomGame(c.BrazilianVariant, b.Copy(), c.WhiteColor)
+		ply := LevelSearcher{Level: level}.Search(g)
+		g.DoPly(ply)
+		if g.Result() != c.WhiteWonResult {
+			t.Errorf("level %d: expected the winning ply, got %v", level, ply)
+		}
+	}
+}
+
+func TestLevelSearcherMissedCapture(t *testing.T) {
+	v := c.AmericanVariant
+	v.CaptureRule = c.CapturesNotMandatory
+	// not capturing loses the pawn
+	b := c.DecodeBoard(`
+		.......x
+		........
+		........
+		........
+		...x....
+		..o.....
+	`)
+	g := c.NewCustomGame(v, b, c.WhiteColor)
+
+	r := rand.New(rand.NewSource(1))
+	missed := 0
+	for i := 0; i < 50; i++ {
+		if captureCount(LevelSearcher{Level: MinLevel, Rand: r}.Search(g)) == 0 {
+			missed++
+		}
+		if captureCount(LevelSearcher{Level: MaxLevel, Rand: r}.Search(g)) == 0 {
+			t.Fatalf("the strongest level shouldn't miss the capture")
+		}
+	}
+	if missed == 0 {
+		t.Errorf("expected the weakest level to miss the capture sometimes")
+	}
+}
+
+func TestFewestCaptures(t *testing.T) {
+	v := c.AmericanVariant
+	v.CaptureRule = c.CapturesNotMandatory
+	g := c.NewCustomGame(v, c.DecodeBoard(`
+		........
+		........
+		...x....
+		........
+		...x....
+		..o.....
+	`), c.WhiteColor)
+	var results []SearchResult
+	for _, p := range g.Plies() {
+		results = append(results, SearchResult{Ply: p})
+	}
+	filtered := fewestCaptures(results)
+	if len(filtered) == 0 || len(filtered) == len(results) {
+		t.Fatalf("expected only the simple moves to be kept, got %v of %v", filtered, results)
+	}
+	for _, r := range filtered {
+		if captureCount(r.Ply) != 0 {
+			t.Errorf("expected only simple moves, got %v", r.Ply)
+		}
+	}
+}
+
+func TestLevelSearcherSingleAndNoPly(t *testing.T) {
+	over := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(".\n.\n.\n...o"), c.BlackColor)
+	if ply := (LevelSearcher{}).Search(over); ply != nil {
+		t.Errorf("expected no ply in a finished game, got %v", ply)
+	}
+
+	// the capture is mandatory
+	g := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(`
+		........
+		........
+		..x.....
+		...o....
+	`), c.WhiteColor)
+	if ply := (LevelSearcher{Level: MaxLevel}).Search(g); !ply.Equals(g.Plies()[0]) {
+		t.Errorf("expected the only ply %v, got %v", g.Plies()[0], ply)
+	}
+}
+
+func TestLevelSearcherCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if ply := (LevelSearcher{Level: MaxLevel}).SearchContext(ctx, c.NewGame()); ply != nil {
+		t.Errorf("expected no ply from a cancelled search, got %v", ply)
+	}
+
+	// cancelled while searching the plies, after some may have been searched
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	evaluated := 0
+	h := func(b *c.Board, player c.Color) float64 {
+		if evaluated++; evaluated == 1000 {
+			cancel()
+		}
+		return WeightedCountHeuristic(b, player)
+	}
+	if ply := (LevelSearcher{Level: MaxLevel, Heuristic: h}).SearchContext(ctx, c.NewGame()); ply != nil {
+		t.Errorf("expected no ply from a search cut short, got %v", ply)
+	}
+}
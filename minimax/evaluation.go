@@ -0,0 +1,209 @@
+package minimax
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Weights of the terms of the heuristic made by Weights.Heuristic.
+// Each term is counted for both players, and the heuristic is the
+// weighted sum of the player's terms minus the opponent's.
+type Weights struct {
+	Pawn float64 `json:"pawn"`
+	King float64 `json:"king"`
+	// Pawns in the opponent's half of the board
+	Advancement float64 `json:"advancement"`
+	// Pawns on the player's own back row, guarding it against the opponent's crowning
+	BackRank float64 `json:"backRank"`
+	// Pieces in the central rows and columns
+	Center float64 `json:"center"`
+	// Simple moves the pieces could make (kings counted as moving a single tile)
+	Mobility float64 `json:"mobility"`
+	// Pawns with no opponent piece ahead of them that could stop them from crowning
+	Runaway float64 `json:"runaway"`
+	// Rows advanced by all of the pawns added up, how developed the player is
+	Tempo float64 `json:"tempo"`
+	// Kings that can't move, usually negative
+	TrappedKing float64 `json:"trappedKing"`
+}
+
+var DefaultWeights = Weights{
+	Pawn:        1,
+	King:        2.5,
+	Advancement: 0.1,
+	BackRank:    0.1,
+	Center:      0.05,
+	Mobility:    0.02,
+	Runaway:     0.5,
+	Tempo:       0.01,
+	TrappedKing: -0.4,
+}
+
+// Reads weights as JSON, e.g. {"pawn": 1, "king": 3}.
+// Weights not given are the same as in DefaultWeights.
+func ReadWeights(r io.Reader) (Weights, error) {
+	w := DefaultWeights
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&w); err != nil {
+		return Weights{}, fmt.Errorf("weights: %w", err)
+	}
+	return w, nil
+}
+
+func WriteWeights(w io.Writer, weights Weights) error {
+	bs, err := json.MarshalIndent(weights, "", "  ")
+	if err != nil {
+		return fmt.Errorf("weights: %w", err)
+	}
+	if _, err := w.Write(append(bs, '\n')); err != nil {
+		return fmt.Errorf("weights: %w", err)
+	}
+	return nil
+}
+
+// Values of the terms for one player
+type terms struct {
+	pawns, kings, advanced, backRank, center, mobility, runaways, tempo, trappedKings int
+}
+
+// The player's terms minus the opponent's, each in the field of the weight it's multiplied by,
+// so that the heuristic is w.Dot(Features(b, player))
+func Features(b *c.Board, player c.Color) Weights {
+	t := evaluationTerms(b)
+	ours, theirs := t[player], t[player.Opposite()]
+	return Weights{
+		Pawn:        float64(ours.pawns - theirs.pawns),
+		King:        float64(ours.kings - theirs.kings),
+		Advancement: float64(ours.advanced - theirs.advanced),
+		BackRank:    float64(ours.backRank - theirs.backRank),
+		Center:      float64(ours.center - theirs.center),
+		Mobility:    float64(ours.mobility - theirs.mobility),
+		Runaway:     float64(ours.runaways - theirs.runaways),
+		Tempo:       float64(ours.tempo - theirs.tempo),
+		TrappedKing: float64(ours.trappedKings - theirs.trappedKings),
+	}
+}
+
+// Sum of the products of each field
+func (w Weights) Dot(o Weights) float64 {
+	return w.Pawn*o.Pawn +
+		w.King*o.King +
+		w.Advancement*o.Advancement +
+		w.BackRank*o.BackRank +
+		w.Center*o.Center +
+		w.Mobility*o.Mobility +
+		w.Runaway*o.Runaway +
+		w.Tempo*o.Tempo +
+		w.TrappedKing*o.TrappedKing
+}
+
+func (w Weights) Heuristic() Heuristic {
+	return func(b *c.Board, player c.Color) float64 {
+		return w.Dot(Features(b, player))
+	}
+}
+
+type evalPiece struct {
+	row, col int
+	c.Color
+	c.Kind
+}
+
+// Row direction the pawns of the color move in
+func forwardOf(color c.Color) int {
+	if color == c.WhiteColor {
+		return -1
+	}
+	return +1
+}
+
+// Terms of each player, indexed by color
+func evaluationTerms(b *c.Board) (t [2]terms) {
+	size := int(b.Size())
+	var pieces []evalPiece
+	for n := 1; n <= c.SquareCount(b.Size()); n++ {
+		row, col, _ := c.SquareCoord(b.Size(), n)
+		if b.IsOccupied(row, col) {
+			color, kind := b.Get(row, col)
+			pieces = append(pieces, evalPiece{int(row), int(col), color, kind})
+		}
+	}
+
+	free := func(row, col int) bool {
+		return row >= 0 && row < size && col >= 0 && col < size && !b.IsOccupied(byte(row), byte(col))
+	}
+	inCenter := func(x int) bool {
+		return x >= size/4 && x < size-size/4
+	}
+
+	for _, p := range pieces {
+		pt := &t[p.Color]
+		if inCenter(p.row) && inCenter(p.col) {
+			pt.center++
+		}
+
+		if p.Kind == c.KingKind {
+			pt.kings++
+			moves := 0
+			for _, drow := range []int{-1, +1} {
+				for _, dcol := range []int{-1, +1} {
+					if free(p.row+drow, p.col+dcol) {
+						moves++
+					}
+				}
+			}
+			pt.mobility += moves
+			if moves == 0 {
+				pt.trappedKings++
+			}
+			continue
+		}
+
+		pt.pawns++
+		forward := forwardOf(p.Color)
+		for _, dcol := range []int{-1, +1} {
+			if free(p.row+forward, p.col+dcol) {
+				pt.mobility++
+			}
+		}
+		// white starts at the bottom of the board and black at the top
+		advanced := p.row
+		if p.Color == c.WhiteColor {
+			advanced = size - 1 - p.row
+		}
+		pt.tempo += advanced
+		if advanced == 0 {
+			pt.backRank++
+		}
+		if advanced >= size/2 {
+			pt.advanced++
+		}
+		if isRunaway(p, pieces) {
+			pt.runaways++
+		}
+	}
+	return t
+}
+
+// Whether no opponent piece is in the cone of squares the pawn could reach going forward
+func isRunaway(pawn evalPiece, pieces []evalPiece) bool {
+	forward := forwardOf(pawn.Color)
+	for _, q := range pieces {
+		if q.Color == pawn.Color {
+			continue
+		}
+		ahead := (q.row - pawn.row) * forward
+		dcol := q.col - pawn.col
+		if dcol < 0 {
+			dcol = -dcol
+		}
+		if ahead > 0 && dcol <= ahead {
+			return false
+		}
+	}
+	return true
+}
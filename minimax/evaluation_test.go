@@ -0,0 +1,132 @@
+package minimax
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+const evaluationBoard = `
+  .#
+	o.o
+	.
+	....x
+	.
+	......x
+	.
+	o
+`
+
+func TestEvaluationTerms(t *testing.T) {
+	got := evaluationTerms(c.DecodeBoard(evaluationBoard))
+
+	// the black king is trapped by the white pawns about to crown,
+	// and only the black pawn on the right has a free way to its crowning row
+	want := [2]terms{
+		c.WhiteColor: {pawns: 3, advanced: 2, backRank: 1, mobility: 2, tempo: 6 + 6 + 0},
+		c.BlackColor: {pawns: 2, kings: 1, advanced: 1, center: 1, mobility: 4, runaways: 1, tempo: 3 + 5, trappedKings: 1},
+	}
+	for _, color := range []c.Color{c.WhiteColor, c.BlackColor} {
+		if got[color] != want[color] {
+			t.Errorf("%v: wanted terms %+v got %+v", color, want[color], got[color])
+		}
+	}
+}
+
+func TestWeightsHeuristic(t *testing.T) {
+	g := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(evaluationBoard), c.WhiteColor)
+	h := Weights{King: 3, TrappedKing: -1, Runaway: 2}.Heuristic()
+	assertHeuristicValue(t, h, g, c.BlackColor, 3-1+2)
+	assertHeuristicValue(t, h, g, c.WhiteColor, -(3 - 1 + 2))
+
+	// same as counting the material
+	h = Weights{Pawn: 1, King: 2}.Heuristic()
+	assertHeuristicValue(t, h, g, c.WhiteColor, WeightedCountHeuristic(g.Board(), c.WhiteColor))
+
+	// both players have the same position
+	for _, v := range []c.Variant{c.BrazilianVariant, c.InternationalVariant} {
+		g := c.NewCustomGame(v, nil, c.WhiteColor)
+		assertHeuristicValue(t, DefaultWeights.Heuristic(), g, c.WhiteColor, 0)
+	}
+}
+
+func TestReadWeights(t *testing.T) {
+	w, err := ReadWeights(strings.NewReader(`{"king": 3, "runaway": 1.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultWeights
+	want.King = 3
+	want.Runaway = 1.5
+	if w != want {
+		t.Errorf("wanted weights %+v got %+v", want, w)
+	}
+
+	for _, s := range []string{`{"queen": 9}`, `{"pawn": "one"}`, `{`} {
+		if _, err := ReadWeights(strings.NewReader(s)); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestHeuristicFromString(t *testing.T) {
+	g := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(evaluationBoard), c.WhiteColor)
+	h := HeuristicFromString("Positional")
+	if h == nil {
+		t.Fatalf("expected the positional heuristic")
+	}
+	assertHeuristicValue(t, h, g, c.WhiteColor, DefaultWeights.Heuristic()(g.Board(), c.WhiteColor))
+
+	if HeuristicFromString("Custom") != nil {
+		t.Errorf("expected no heuristic before registering it")
+	}
+	RegisterHeuristic("Custom", Weights{Runaway: 1}.Heuristic())
+	if h := HeuristicFromString("Custom"); h == nil {
+		t.Errorf("expected the registered heuristic")
+	} else {
+		assertHeuristicValue(t, h, g, c.BlackColor, 1)
+	}
+}
+
+func TestSearchPositional(t *testing.T) {
+	g := c.NewGame()
+	s := DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: HeuristicFromString("Positional"), DepthLimit: 4}
+	for i := 0; i < 4 && !g.Result().Over(); i++ {
+		s.ToMax = g.ToPlay()
+		ply := s.SearchContext(context.Background(), g)
+		assertLegalPly(t, g, ply)
+		g.DoPly(ply)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	boards := []*c.Board{c.DecodeBoard(evaluationBoard), c.NewGame().Board()}
+	weights := []Weights{DefaultWeights, {Pawn: 1, Tempo: -0.3, Center: 2}}
+	for _, b := range boards {
+		for _, w := range weights {
+			for _, player := range []c.Color{c.WhiteColor, c.BlackColor} {
+				want := w.Heuristic()(b, player)
+				if got := w.Dot(Features(b, player)); got != want {
+					t.Errorf("%v: wanted %g got %g", player, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestWriteWeights(t *testing.T) {
+	var buf strings.Builder
+	w := Weights{Pawn: 1, King: 3.25, TrappedKing: -0.5}
+	if err := WriteWeights(&buf, w); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadWeights(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != w {
+		t.Errorf("wanted weights %+v read back, got %+v", w, read)
+	}
+}
@@ -14,14 +14,25 @@ func (h Heuristic) String() string {
 	return fmt.Sprintf("%q", runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name())
 }
 
+// Heuristics registered with RegisterHeuristic
+var registeredHeuristics = map[string]Heuristic{}
+
+// Makes the heuristic available by name in HeuristicFromString, e.g. one made of weights read from a file.
+// Should be called before any searches start, since it isn't safe for concurrent use.
+func RegisterHeuristic(name string, h Heuristic) {
+	registeredHeuristics[name] = h
+}
+
 func HeuristicFromString(s string) Heuristic {
 	switch s {
 	case "UnweightedCount":
 		return UnweightedCountHeuristic
 	case "WeightedCount":
 		return WeightedCountHeuristic
+	case "Positional":
+		return DefaultWeights.Heuristic()
 	default:
-		return nil
+		return registeredHeuristics[s]
 	}
 }
 
@@ -1,11 +1,13 @@
 package minimax
 
 import (
+	"context"
 	"math"
 	"math/rand"
 	"time"
 
 	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/tablebase"
 )
 
 const (
@@ -14,8 +16,12 @@ const (
 )
 
 // A Searcher searches for the best ply in the game tree of the given game.
+// SearchContext stops searching when the context is done, returning the best
+// ply found so far, or nil if it couldn't find one; Search is the same as
+// SearchContext with context.Background().
 type Searcher interface {
 	Search(g *c.Game) c.Ply
+	SearchContext(ctx context.Context, g *c.Game) c.Ply
 }
 
 // A DepthLimitedSearcher is a Searcher that stops searching the game tree
@@ -26,14 +32,65 @@ type DepthLimitedSearcher struct {
 	ToMax c.Color
 	Heuristic
 	DepthLimit int
+	// Optional, a new table is used in each search if nil.
+	// Passing the same table to consecutive searches lets them reuse previous results.
+	Table *TranspositionTable
+	// Searches plies that look equally good in random order,
+	// so the searcher doesn't always play the same way
+	Randomize bool
+	// Maximum number of plies of captures searched past the depth limit,
+	// so leaves aren't evaluated in the middle of a trade.
+	// DefaultQuiescenceDepth if zero, negative disables the quiescence search.
+	QuiescenceDepth int
+	// Optional, positions in it are evaluated by their exact result instead of searched
+	// (which doesn't take the draw rules into account, see tablebase.Tablebase)
+	Tablebase *tablebase.Tablebase
 }
 
-var _ Searcher = DepthLimitedSearcher{}
+var _ Analyzer = DepthLimitedSearcher{}
+
+func tableOrNew(tt *TranspositionTable) *TranspositionTable {
+	if tt == nil {
+		return NewTranspositionTable(DefaultTranspositionTableSize)
+	}
+	tt.newSearch()
+	return tt
+}
 
 func (s DepthLimitedSearcher) Search(g *c.Game) c.Ply {
-	ctx := searchContext{toMax: s.ToMax, h: s.Heuristic, timedCloser: nil}
-	_, ply := ctx.search(g, s.DepthLimit, math.Inf(-1), math.Inf(1))
-	return ply
+	return s.SearchContext(context.Background(), g)
+}
+
+// Returns nil if the context is done before the search finishes,
+// since the search can't be trusted if it didn't go through the whole tree
+func (s DepthLimitedSearcher) SearchContext(ctx context.Context, g *c.Game) c.Ply {
+	return s.Analyze(ctx, g).Ply
+}
+
+func (s DepthLimitedSearcher) newContext(ctx context.Context, g *c.Game) searchContext {
+	return searchContext{
+		toMax:      s.ToMax,
+		h:          s.Heuristic,
+		closer:     ctx.Done(),
+		tt:         tableOrNew(s.Table),
+		variantKey: variantKey(g.Variant()),
+		ord:        newOrderer(s.Randomize),
+		stats:      new(searchStats),
+
+		quiescenceDepth: quiescenceDepthOrDefault(s.QuiescenceDepth),
+		tb:              s.Tablebase,
+	}
+}
+
+// If the context is done before the search finishes, only the number of nodes and the time elapsed are given
+func (s DepthLimitedSearcher) Analyze(ctx context.Context, g *c.Game) SearchResult {
+	start := time.Now()
+	sc := s.newContext(ctx, g)
+	value, ply := sc.search(g, s.DepthLimit, 0, math.Inf(-1), math.Inf(1))
+	if sc.closed() {
+		return sc.result(g, nil, 0, 0, start)
+	}
+	return sc.result(g, ply, value, s.DepthLimit, start)
 }
 
 // A TimeLimitedSearcher is a Searcher that stops searching the game tree
@@ -43,34 +100,82 @@ type TimeLimitedSearcher struct {
 	ToMax c.Color
 	Heuristic
 	TimeLimit time.Duration
+	// Optional, a new table is used in each search if nil.
+	// Each iteration of the iterative deepening reuses the results of the previous ones through it.
+	Table *TranspositionTable
+	// Same as in DepthLimitedSearcher
+	Randomize       bool
+	QuiescenceDepth int
+	Tablebase       *tablebase.Tablebase
+	// Optional, receives the result of each depth searched
+	Progress ProgressFunc
 }
 
-var _ Searcher = TimeLimitedSearcher{}
+var _ Analyzer = TimeLimitedSearcher{}
 
 func (s TimeLimitedSearcher) Search(g *c.Game) c.Ply {
-	tlim := s.TimeLimit
+	return s.SearchContext(context.Background(), g)
+}
+
+func clampTimeLimit(tlim time.Duration) time.Duration {
 	if tlim < MinTimeLimit {
 		tlim = MinTimeLimit
 	}
 	if tlim > MaxTimeLimit {
 		tlim = MaxTimeLimit
 	}
+	return tlim
+}
+
+// The time limit is one more deadline for the context;
+// the search stops at whichever comes first
+func (s TimeLimitedSearcher) SearchContext(ctx context.Context, g *c.Game) c.Ply {
+	return s.Analyze(ctx, g).Ply
+}
+
+func (s TimeLimitedSearcher) newContext(ctx context.Context, g *c.Game) searchContext {
+	return DepthLimitedSearcher{
+		ToMax:           s.ToMax,
+		Heuristic:       s.Heuristic,
+		Table:           s.Table,
+		Randomize:       s.Randomize,
+		QuiescenceDepth: s.QuiescenceDepth,
+		Tablebase:       s.Tablebase,
+	}.newContext(ctx, g)
+}
+
+func (s TimeLimitedSearcher) Analyze(ctx context.Context, g *c.Game) SearchResult {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, clampTimeLimit(s.TimeLimit))
+	defer cancel()
 
-	stopTime := time.Now().Add(tlim)
+	stopTime, _ := ctx.Deadline()
 
 	var ply c.Ply
-	ctx := searchContext{toMax: s.ToMax, h: s.Heuristic, timedCloser: closeAfter(tlim)}
+	var value float64
+	depth := 0
+	sc := s.newContext(ctx, g)
 	for dlim := 1; ; dlim++ {
 		// We can only assign the result of a search (variable ply0) to the best known ply so far (variable ply)
 		// if the ply0 search went all the way to the end. Otherwise, it's possible that the search has
 		// stopped in a node at an early depth in the tree, *which might have a large heuristic value
 		// without it actually being a good move*
 		searchStart := time.Now()
-		_, ply0 := ctx.search(g, dlim, math.Inf(-1), math.Inf(1))
-		if ctx.closed() {
+		// the best ply of the previous iteration is searched first
+		sc.pv = ply
+		value0, ply0 := sc.search(g, dlim, 0, math.Inf(-1), math.Inf(1))
+		if sc.closed() {
+			break
+		}
+		ply, value, depth = ply0, value0, dlim
+		if s.Progress != nil {
+			s.Progress(sc.result(g, ply, value, depth, start))
+		}
+
+		// no need to search deeper when the game is over or its end is already known
+		if ply == nil || mateIn(value) != 0 {
 			break
 		}
-		ply = ply0
 
 		searchDuration := time.Since(searchStart)
 		timeLeft := time.Until(stopTime)
@@ -79,27 +184,35 @@ func (s TimeLimitedSearcher) Search(g *c.Game) c.Ply {
 		}
 	}
 
-	return ply
+	return sc.result(g, ply, value, depth, start)
 }
 
-type timedCloser <-chan struct{}
-
-func closeAfter(d time.Duration) timedCloser {
-	ch := make(chan struct{})
-	go func() {
-		time.Sleep(d)
-		close(ch)
-	}()
-	return ch
-}
+// Closed when the search should stop, nil never closes
+type closer <-chan struct{}
 
 type searchContext struct {
 	toMax c.Color
-	timedCloser
+	closer
 	h Heuristic
+	// nil disables the transposition table
+	tt *TranspositionTable
+	// of the variant of the game searched (see ttKey)
+	variantKey uint64
+	// nil disables move ordering (except for the best ply from the table)
+	ord *orderer
+	// ply to search first at the root
+	pv c.Ply
+	// plies of captures searched past the depth limit, 0 disables the quiescence search
+	quiescenceDepth int
+	// nil disables counting nodes
+	stats *searchStats
+	// plies not to search at the root (see multiPV)
+	exclude []c.Ply
+	// nil disables probing the tablebase
+	tb *tablebase.Tablebase
 }
 
-func (c timedCloser) closed() bool {
+func (c closer) closed() bool {
 	select {
 	case <-c:
 		return true
@@ -114,23 +227,84 @@ const (
 	lossValue = -1_000_000
 )
 
-func (ctx searchContext) search(g *c.Game, depthLeft int, alpha float64, beta float64) (float64, c.Ply) {
-	res := g.Result()
-	if res.Over() {
-		if !res.HasWinner() {
-			return drawValue, nil
-		} else if ctx.toMax == res.Winner() {
-			return winValue, nil
-		} else {
-			return lossValue, nil
-		}
+// Wins and losses are offset by the height at which they happen (see mateThreshold)
+func (ctx searchContext) resultValue(res c.GameResult, height int) float64 {
+	if !res.HasWinner() {
+		return drawValue
+	} else if ctx.toMax == res.Winner() {
+		return winValue - float64(height)
+	} else {
+		return lossValue + float64(height)
+	}
+}
+
+// Same as the value of the result the game ends in, r.Distance plies later
+func (ctx searchContext) tablebaseValue(r tablebase.Result, toPlay c.Color, height int) float64 {
+	if r.Outcome == tablebase.Draw {
+		return drawValue
+	}
+	winner := toPlay
+	if r.Outcome == tablebase.Loss {
+		winner = toPlay.Opposite()
+	}
+	if winner == ctx.toMax {
+		return winValue - float64(height+r.Distance)
 	}
-	if ctx.closed() || depthLeft <= 0 {
+	return lossValue + float64(height+r.Distance)
+}
+
+// height is the number of plies from the root of the search
+func (ctx searchContext) search(g *c.Game, depthLeft int, height int, alpha float64, beta float64) (float64, c.Ply) {
+	ctx.stats.add()
+	if res := g.Result(); res.Over() {
+		return ctx.resultValue(res, height), nil
+	}
+	if ctx.closed() {
 		return ctx.h(g.Board(), ctx.toMax), nil
 	}
+	// the root still has to be searched to find the best ply
+	if ctx.tb != nil && height > 0 {
+		if r, ok := ctx.tb.Probe(g); ok {
+			return ctx.tablebaseValue(r, g.ToPlay(), height), nil
+		}
+	}
+	if depthLeft <= 0 {
+		return ctx.quiesce(g, ctx.quiescenceDepth, height, alpha, beta), nil
+	}
 
-	plies := g.Plies()
-	plies = shuffle(plies)
+	// the table only has the best ply at the root, which may be one of the excluded
+	useTable := ctx.tt != nil && (height > 0 || len(ctx.exclude) == 0)
+
+	var key uint64
+	var ttPly c.Ply
+	if useTable {
+		key = ctx.ttKey(g)
+		if e, ok := ctx.tt.probe(key); ok {
+			if int(e.depth) >= depthLeft {
+				value := fromTableValue(e.value, height)
+				switch e.bound {
+				case ExactBound:
+					return value, e.ply
+				case LowerBound:
+					if value >= beta {
+						return value, e.ply
+					}
+				case UpperBound:
+					if value <= alpha {
+						return value, e.ply
+					}
+				}
+			}
+			ttPly = e.ply
+		}
+	}
+	alpha0, beta0 := alpha, beta
+
+	best := ttPly
+	if height == 0 && ctx.pv != nil {
+		best = ctx.pv
+	}
+	plies := ctx.ord.order(g.Plies(), best, height, g.ToPlay())
 
 	maximizeTurn := g.ToPlay() == ctx.toMax
 
@@ -145,9 +319,12 @@ func (ctx searchContext) search(g *c.Game, depthLeft int, alpha float64, beta fl
 	// (like making one AI that should be better than the other always lose)
 
 	for _, subPly := range plies {
+		if height == 0 && ctx.excluded(subPly) {
+			continue
+		}
 		undoInfo, _ := g.DoPly(subPly)
-
-		subValue, _ := ctx.search(g, depthLeft-1, alpha, beta)
+		subValue, _ := ctx.search(g, depthLeft-1, height+1, alpha, beta)
+		g.UndoPly(undoInfo)
 
 		if maximizeTurn {
 			if subValue > value {
@@ -155,8 +332,8 @@ func (ctx searchContext) search(g *c.Game, depthLeft int, alpha float64, beta fl
 				alpha = math.Max(alpha, subValue)
 			}
 			if subValue >= beta {
-				g.UndoPly(undoInfo)
-				return value, ply
+				ctx.ord.cutoff(subPly, height, depthLeft, g.ToPlay())
+				break
 			}
 		} else {
 			if subValue <= value {
@@ -164,12 +341,21 @@ func (ctx searchContext) search(g *c.Game, depthLeft int, alpha float64, beta fl
 				beta = math.Min(beta, subValue)
 			}
 			if subValue <= alpha {
-				g.UndoPly(undoInfo)
-				return value, ply
+				ctx.ord.cutoff(subPly, height, depthLeft, g.ToPlay())
+				break
 			}
 		}
+	}
 
-		g.UndoPly(undoInfo)
+	// a search interrupted by the timer doesn't give reliable values
+	if useTable && !ctx.closed() {
+		bound := ExactBound
+		if value <= alpha0 {
+			bound = UpperBound
+		} else if value >= beta0 {
+			bound = LowerBound
+		}
+		ctx.tt.store(key, depthLeft, toTableValue(value, height), bound, ply)
 	}
 
 	return value, ply
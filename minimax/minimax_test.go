@@ -1,12 +1,14 @@
 package minimax
 
 import (
+	"context"
 	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/luc527/go_checkers/core"
 	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/tablebase"
 )
 
 func TestDoUndoMinimax(t *testing.T) {
@@ -52,18 +54,60 @@ func TestDoUndoMinimax(t *testing.T) {
 	}
 }
 
-func TestCloseAfter(t *testing.T) {
-	c := closeAfter(200 * time.Millisecond)
-
-	<-time.After(100 * time.Millisecond)
+func TestCloser(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := closer(ctx.Done())
 	if c.closed() {
 		t.Fail()
 	}
-
-	<-time.After(200 * time.Millisecond)
+	cancel()
 	if !c.closed() {
 		t.Fail()
 	}
+
+	if closer(context.Background().Done()).closed() {
+		t.Errorf("a background context never closes")
+	}
+}
+
+func TestSearchContextCancel(t *testing.T) {
+	g := c.NewCustomGame(c.InternationalVariant, nil, c.WhiteColor)
+	searchers := []Searcher{
+		DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 50},
+		TimeLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, TimeLimit: MaxTimeLimit},
+		ParallelSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 50, Workers: 2},
+	}
+	for _, s := range searchers {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		ply := s.SearchContext(ctx, g)
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("%T didn't stop when the context was cancelled, took %v", s, elapsed)
+		}
+		if _, ok := s.(DepthLimitedSearcher); ok && ply != nil {
+			t.Errorf("expected no ply from an unfinished depth limited search")
+		}
+		if _, ok := s.(TimeLimitedSearcher); ok {
+			assertLegalPly(t, g, ply)
+		}
+		cancel()
+	}
+}
+
+func TestSearchContextDeadline(t *testing.T) {
+	g := c.NewGame()
+	s := TimeLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, TimeLimit: MaxTimeLimit}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	ply := s.SearchContext(ctx, g)
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("expected the context deadline to come before the time limit, took %v", elapsed)
+	}
+	assertLegalPly(t, g, ply)
 }
 
 func TestTimeLimitedSearcher(t *testing.T) {
@@ -115,3 +159,68 @@ func TestTimeLimitedSearcher(t *testing.T) {
 		}
 	}
 }
+
+func TestSearch10x10(t *testing.T) {
+	g := c.NewCustomGame(c.InternationalVariant, nil, c.WhiteColor)
+	s := DepthLimitedSearcher{
+		ToMax:      c.WhiteColor,
+		DepthLimit: 3,
+		Heuristic:  WeightedCountHeuristic,
+	}
+	ply := s.Search(g)
+	found := false
+	for _, p := range g.Plies() {
+		if p.Equals(ply) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("searcher returned a ply that is not legal: %v", ply)
+	}
+}
+
+func TestSearchWithTablebase(t *testing.T) {
+	v := c.AmericanVariant
+	v.Size = 6
+	tb, err := tablebase.Generate(v, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := c.DecodeSizedBoard(6, `
+	  .....#
+		.
+		.
+		.
+		.
+		@.@
+	`)
+	g := c.NewCustomGame(v, b, c.WhiteColor)
+	r, ok := tb.Probe(g)
+	if !ok || r.Outcome != tablebase.Win || r.Distance < 3 {
+		t.Fatalf("expected a long win for white, got %+v", r)
+	}
+
+	white := DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 1, Tablebase: tb}
+	if res := white.Analyze(context.Background(), g); res.MateIn != r.Distance {
+		t.Errorf("expected mate in %d, got %d", r.Distance, res.MateIn)
+	}
+
+	// both play perfectly: white wins as soon as possible, black loses as late as possible
+	black := TimeLimitedSearcher{ToMax: c.BlackColor, Heuristic: WeightedCountHeuristic, Tablebase: tb}
+	plies := 0
+	for !g.Result().Over() {
+		var ply c.Ply
+		if g.ToPlay() == c.WhiteColor {
+			ply = white.Search(g)
+		} else {
+			ply = black.Search(g)
+		}
+		if _, err := g.DoPly(ply); err != nil {
+			t.Fatal(err)
+		}
+		plies++
+	}
+	if g.Result() != c.WhiteWonResult || plies != r.Distance {
+		t.Errorf("expected white to win in %d plies, got %v in %d", r.Distance, g.Result(), plies)
+	}
+}
@@ -0,0 +1,96 @@
+package minimax
+
+import (
+	"context"
+	"math"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Multi-PV analysis finds the n best plies at the root instead of only the best one,
+// each with its own value and principal variation: the root is searched once for
+// each of them, excluding the plies found by the searches before.
+// The results are sorted from the best ply to the worst for the player to play,
+// and there are fewer than n of them if the game has fewer plies.
+
+func (ctx searchContext) excluded(p c.Ply) bool {
+	for _, e := range ctx.exclude {
+		if e.Equals(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// The second return value is false if the context was done before all the searches finished
+func (ctx searchContext) multiPV(g *c.Game, depth int, n int, start time.Time) ([]SearchResult, bool) {
+	ctx.exclude = nil
+	var results []SearchResult
+	for len(results) < n {
+		value, ply := ctx.search(g, depth, 0, math.Inf(-1), math.Inf(1))
+		if ctx.closed() {
+			return results, false
+		}
+		if ply == nil {
+			// all plies were found
+			break
+		}
+		results = append(results, ctx.result(g, ply, value, depth, start))
+		ctx.exclude = append(ctx.exclude, ply)
+	}
+	return results, true
+}
+
+// Returns nil if the context is done before all the searches finish
+func (s DepthLimitedSearcher) AnalyzeMultiPV(ctx context.Context, g *c.Game, n int) []SearchResult {
+	sc := s.newContext(ctx, g)
+	results, ok := sc.multiPV(g, s.DepthLimit, n, time.Now())
+	if !ok {
+		return nil
+	}
+	return results
+}
+
+// Returns the results of the deepest iteration in which all n plies were searched,
+// nil if not even the first one finished. Progress is not called.
+func (s TimeLimitedSearcher) AnalyzeMultiPV(ctx context.Context, g *c.Game, n int) []SearchResult {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, clampTimeLimit(s.TimeLimit))
+	defer cancel()
+
+	stopTime, _ := ctx.Deadline()
+
+	var results []SearchResult
+	sc := s.newContext(ctx, g)
+	for dlim := 1; ; dlim++ {
+		searchStart := time.Now()
+		if len(results) > 0 {
+			sc.pv = results[0].Ply
+		}
+		results0, ok := sc.multiPV(g, dlim, n, start)
+		if !ok {
+			break
+		}
+		results = results0
+
+		if allMates(results) {
+			break
+		}
+		if time.Since(searchStart) >= time.Until(stopTime) {
+			break
+		}
+	}
+
+	return results
+}
+
+// True when there's no need to search deeper (including when there are no plies)
+func allMates(results []SearchResult) bool {
+	for _, r := range results {
+		if r.MateIn == 0 {
+			return false
+		}
+	}
+	return true
+}
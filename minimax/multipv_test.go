@@ -0,0 +1,96 @@
+package minimax
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func assertMultiPV(t *testing.T, g *c.Game, results []SearchResult, n int, toMax c.Color) {
+	t.Helper()
+	if len(results) != n {
+		t.Fatalf("expected %d results, got %d", n, len(results))
+	}
+	for i, r := range results {
+		assertLegalPly(t, g, r.Ply)
+		if len(r.PV) == 0 || !r.PV[0].Equals(r.Ply) {
+			t.Errorf("result %d: wrong principal variation %v for ply %v", i, r.PV, r.Ply)
+		}
+		for j := 0; j < i; j++ {
+			if results[j].Ply.Equals(r.Ply) {
+				t.Errorf("results %d and %d have the same ply %v", j, i, r.Ply)
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		// best to worst for the player to play
+		prev := results[i-1].Value
+		if (g.ToPlay() == toMax && r.Value > prev) || (g.ToPlay() != toMax && r.Value < prev) {
+			t.Errorf("result %d has value %g, out of order after %g", i, r.Value, prev)
+		}
+	}
+}
+
+func TestMultiPV(t *testing.T) {
+	g := c.NewGame()
+	for _, toMax := range []c.Color{c.WhiteColor, c.BlackColor} {
+		s := DepthLimitedSearcher{ToMax: toMax, Heuristic: WeightedCountHeuristic, DepthLimit: 4}
+		results := s.AnalyzeMultiPV(context.Background(), g, 3)
+		assertMultiPV(t, g, results, 3, toMax)
+
+		if best := s.Analyze(context.Background(), g); best.Value != results[0].Value {
+			t.Errorf("the best value %g should be the same as a single search's %g", results[0].Value, best.Value)
+		}
+
+		// the value of each ply is the value of searching the position after it
+		ctx := searchContext{toMax: toMax, h: WeightedCountHeuristic, quiescenceDepth: DefaultQuiescenceDepth}
+		for _, r := range results {
+			undo, _ := g.DoPly(r.Ply)
+			value, _ := ctx.search(g, 3, 1, -winValue*2, winValue*2)
+			g.UndoPly(undo)
+			if value != r.Value {
+				t.Errorf("ply %v: expected value %g, got %g", r.Ply, value, r.Value)
+			}
+		}
+	}
+}
+
+func TestMultiPVAllPlies(t *testing.T) {
+	g := c.NewGame()
+	n := len(g.Plies())
+	s := DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 3, Table: NewTranspositionTable(1 << 10)}
+	assertMultiPV(t, g, s.AnalyzeMultiPV(context.Background(), g, n+5), n, c.WhiteColor)
+
+	over := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(".\n.\n.\n...o"), c.BlackColor)
+	if results := s.AnalyzeMultiPV(context.Background(), over, 3); len(results) != 0 {
+		t.Errorf("expected no results for a game that is over, got %v", results)
+	}
+}
+
+func TestMultiPVTimeLimited(t *testing.T) {
+	g := c.NewGame()
+	s := TimeLimitedSearcher{ToMax: c.BlackColor, Heuristic: WeightedCountHeuristic, TimeLimit: 100 * time.Millisecond}
+	start := time.Now()
+	results := s.AnalyzeMultiPV(context.Background(), g, 3)
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("multi-PV search took too long: %v", elapsed)
+	}
+	assertMultiPV(t, g, results, 3, c.BlackColor)
+	for _, r := range results[1:] {
+		if r.Depth != results[0].Depth {
+			t.Errorf("expected all results from the same depth, got %d and %d", r.Depth, results[0].Depth)
+		}
+	}
+}
+
+func TestMultiPVCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 5}
+	if results := s.AnalyzeMultiPV(ctx, c.NewGame(), 3); results != nil {
+		t.Errorf("expected no results from a cancelled search, got %v", results)
+	}
+}
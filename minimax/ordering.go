@@ -0,0 +1,163 @@
+package minimax
+
+import (
+	"sort"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Plies are searched in this order: the best ply known for the position
+// (from the previous iteration or the transposition table), then captures,
+// the ones capturing more pieces first, then killer moves (quiet plies that
+// caused cutoffs at the same height in other branches), then the other
+// quiet plies by their history score (how much they caused cutoffs anywhere).
+const (
+	bestPlyScore = 1 << 30
+	captureScore = 1 << 20
+	killerScore  = 1 << 19
+	// history scores are kept below killerScore
+	maxHistoryScore = killerScore - 1
+)
+
+const killersPerHeight = 2
+
+const tileCount = c.MaxBoardSize * c.MaxBoardSize
+
+type orderer struct {
+	killers [][killersPerHeight]c.Ply
+	history [2][tileCount][tileCount]int32
+	// shuffle plies with the same score, otherwise they're kept in the order they were generated
+	random bool
+}
+
+func newOrderer(random bool) *orderer {
+	return &orderer{random: random}
+}
+
+func captureCount(p c.Ply) int {
+	n := 0
+	for _, ins := range p {
+		if ins.Type() == c.CaptureInstruction {
+			n++
+		}
+	}
+	return n
+}
+
+// Tiles where the ply starts and ends, as indices in the history table
+func plyEnds(p c.Ply) (from, to int, ok bool) {
+	for _, ins := range p {
+		if ins.Type() != c.MoveInstruction {
+			continue
+		}
+		if !ok {
+			from = int(ins.Row())*c.MaxBoardSize + int(ins.Col())
+			ok = true
+		}
+		row, col := ins.Destination()
+		to = int(row)*c.MaxBoardSize + int(col)
+	}
+	return
+}
+
+func (o *orderer) isKiller(p c.Ply, height int) int {
+	if height >= len(o.killers) {
+		return -1
+	}
+	for i, k := range o.killers[height] {
+		if k != nil && k.Equals(p) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o *orderer) score(p c.Ply, best c.Ply, height int, color c.Color) int {
+	if best != nil && p.Equals(best) {
+		return bestPlyScore
+	}
+	if n := captureCount(p); n > 0 {
+		return captureScore + n
+	}
+	if i := o.isKiller(p, height); i >= 0 {
+		return killerScore - i
+	}
+	if from, to, ok := plyEnds(p); ok {
+		return int(o.history[color][from][to])
+	}
+	return 0
+}
+
+// Returns a copy of the plies in the order they should be searched.
+// A nil orderer only puts the best ply first.
+func (o *orderer) order(plies []c.Ply, best c.Ply, height int, color c.Color) []c.Ply {
+	ordered := make([]c.Ply, len(plies))
+	copy(ordered, plies)
+
+	if o == nil {
+		for i := range ordered {
+			if best != nil && ordered[i].Equals(best) {
+				ordered[0], ordered[i] = ordered[i], ordered[0]
+				break
+			}
+		}
+		return ordered
+	}
+
+	if o.random {
+		ordered = shuffle(ordered)
+	}
+	scores := make([]int, len(ordered))
+	for i, p := range ordered {
+		scores[i] = o.score(p, best, height, color)
+	}
+	sort.Stable(byScore{ordered, scores})
+	return ordered
+}
+
+type byScore struct {
+	plies  []c.Ply
+	scores []int
+}
+
+func (s byScore) Len() int           { return len(s.plies) }
+func (s byScore) Less(i, j int) bool { return s.scores[i] > s.scores[j] }
+func (s byScore) Swap(i, j int) {
+	s.plies[i], s.plies[j] = s.plies[j], s.plies[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+}
+
+// Records that the ply caused a cutoff
+func (o *orderer) cutoff(p c.Ply, height int, depthLeft int, color c.Color) {
+	if o == nil || captureCount(p) > 0 {
+		return
+	}
+
+	for height >= len(o.killers) {
+		o.killers = append(o.killers, [killersPerHeight]c.Ply{})
+	}
+	if o.isKiller(p, height) != 0 {
+		killers := &o.killers[height]
+		copy(killers[1:], killers[:killersPerHeight-1])
+		killers[0] = p
+	}
+
+	if from, to, ok := plyEnds(p); ok {
+		h := &o.history[color][from][to]
+		*h += int32(depthLeft * depthLeft)
+		if *h > maxHistoryScore {
+			o.ageHistory()
+		}
+	}
+}
+
+// Halves all history scores, keeping their order but letting recent cutoffs weigh more
+func (o *orderer) ageHistory() {
+	for color := range o.history {
+		for from := range o.history[color] {
+			for to := range o.history[color][from] {
+				o.history[color][from][to] /= 2
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package minimax
+
+import (
+	"math"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+var (
+	quietA   = c.Ply{c.MakeMoveInstruction(5, 0, 4, 1)}
+	quietB   = c.Ply{c.MakeMoveInstruction(5, 2, 4, 3)}
+	quietC   = c.Ply{c.MakeMoveInstruction(5, 4, 4, 5)}
+	capture1 = c.Ply{
+		c.MakeMoveInstruction(5, 6, 3, 4),
+		c.MakeCaptureInstruction(4, 5, c.BlackColor, c.PawnKind),
+	}
+	capture2 = c.Ply{
+		c.MakeMoveInstruction(5, 6, 3, 4),
+		c.MakeCaptureInstruction(4, 5, c.BlackColor, c.PawnKind),
+		c.MakeMoveInstruction(3, 4, 1, 2),
+		c.MakeCaptureInstruction(2, 3, c.BlackColor, c.PawnKind),
+	}
+)
+
+func assertOrder(t *testing.T, got, want []c.Ply) {
+	t.Helper()
+	if !c.PliesEquals(got, want) {
+		t.Errorf("wanted order\n%v\ngot\n%v", want, got)
+	}
+}
+
+func TestOrderPlies(t *testing.T) {
+	plies := []c.Ply{quietA, quietB, capture1, quietC, capture2}
+	o := newOrderer(false)
+
+	got := o.order(plies, nil, 0, c.WhiteColor)
+	assertOrder(t, got, []c.Ply{capture2, capture1, quietA, quietB, quietC})
+
+	got = o.order(plies, quietC, 0, c.WhiteColor)
+	assertOrder(t, got, []c.Ply{quietC, capture2, capture1, quietA, quietB})
+
+	// history: quietB caused a cutoff somewhere
+	o.cutoff(quietB, 5, 3, c.WhiteColor)
+	got = o.order(plies, nil, 0, c.WhiteColor)
+	assertOrder(t, got, []c.Ply{capture2, capture1, quietB, quietA, quietC})
+	// only for the player who made it
+	got = o.order(plies, nil, 0, c.BlackColor)
+	assertOrder(t, got, []c.Ply{capture2, capture1, quietA, quietB, quietC})
+
+	// killers: quietC caused a cutoff at this height, so it goes before quietB
+	// even though it has a lower history score
+	o.cutoff(quietC, 2, 1, c.WhiteColor)
+	got = o.order(plies, nil, 2, c.WhiteColor)
+	assertOrder(t, got, []c.Ply{capture2, capture1, quietC, quietB, quietA})
+
+	// the plies given are left as they were
+	assertOrder(t, plies, []c.Ply{quietA, quietB, capture1, quietC, capture2})
+}
+
+func TestOrderPliesWithoutOrderer(t *testing.T) {
+	var o *orderer
+	plies := []c.Ply{quietA, quietB, capture1}
+	assertOrder(t, o.order(plies, capture1, 0, c.WhiteColor), []c.Ply{capture1, quietB, quietA})
+	assertOrder(t, o.order(plies, nil, 0, c.WhiteColor), plies)
+	o.cutoff(quietA, 0, 1, c.WhiteColor)
+}
+
+func TestOrderPliesRandom(t *testing.T) {
+	plies := []c.Ply{quietA, quietB, quietC, capture1}
+	o := newOrderer(true)
+	for i := 0; i < 20; i++ {
+		got := o.order(plies, nil, 0, c.WhiteColor)
+		if !got[0].Equals(capture1) || len(got) != len(plies) {
+			t.Errorf("expected the capture first, got %v", got)
+		}
+	}
+}
+
+func TestKillers(t *testing.T) {
+	o := newOrderer(false)
+
+	o.cutoff(capture1, 3, 1, c.WhiteColor)
+	if o.isKiller(capture1, 3) >= 0 {
+		t.Errorf("captures shouldn't be killers")
+	}
+
+	o.cutoff(quietA, 3, 1, c.WhiteColor)
+	o.cutoff(quietB, 3, 1, c.WhiteColor)
+	if o.isKiller(quietB, 3) != 0 || o.isKiller(quietA, 3) != 1 {
+		t.Errorf("wrong killers %v", o.killers[3])
+	}
+	o.cutoff(quietA, 3, 1, c.WhiteColor)
+	if o.isKiller(quietA, 3) != 0 || o.isKiller(quietB, 3) != 1 {
+		t.Errorf("wrong killers %v", o.killers[3])
+	}
+	o.cutoff(quietA, 3, 1, c.WhiteColor)
+	o.cutoff(quietC, 3, 1, c.WhiteColor)
+	if o.isKiller(quietC, 3) != 0 || o.isKiller(quietA, 3) != 1 || o.isKiller(quietB, 3) >= 0 {
+		t.Errorf("wrong killers %v", o.killers[3])
+	}
+	if o.isKiller(quietC, 2) >= 0 || o.isKiller(quietC, 10) >= 0 {
+		t.Errorf("killers are kept by height")
+	}
+}
+
+func TestHistoryAging(t *testing.T) {
+	o := newOrderer(false)
+	for i := 0; i < 10_000; i++ {
+		o.cutoff(quietA, 0, 20, c.WhiteColor)
+	}
+	from, to, _ := plyEnds(quietA)
+	if h := o.history[c.WhiteColor][from][to]; h <= 0 || h > maxHistoryScore {
+		t.Errorf("history score out of range: %d", h)
+	}
+	if o.score(quietA, nil, 1, c.WhiteColor) >= killerScore {
+		t.Errorf("history scores should stay below killers")
+	}
+}
+
+func TestOrderingKeepsSearchValue(t *testing.T) {
+	b := c.DecodeBoard(`
+	  .x.x.x.x
+		x.x.x.x.
+		...x.x.x
+		..x
+		...o
+		o.o...o.
+		.o.o.o.o
+		o.o.o.o.
+	`)
+	g := c.NewCustomGame(c.BrazilianVariant, b, c.WhiteColor)
+	for _, toMax := range []c.Color{c.WhiteColor, c.BlackColor} {
+		plain := searchContext{toMax: toMax, h: WeightedCountHeuristic}
+		ordered := plain
+		ordered.ord = newOrderer(false)
+
+		want, _ := plain.search(g, 5, 0, math.Inf(-1), math.Inf(1))
+		got, _ := ordered.search(g, 5, 0, math.Inf(-1), math.Inf(1))
+		if got != want {
+			t.Errorf("wanted value %g got %g with move ordering", want, got)
+		}
+	}
+}
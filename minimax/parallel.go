@@ -0,0 +1,200 @@
+package minimax
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/tablebase"
+)
+
+// A ParallelSearcher is a Searcher that searches the game tree on several goroutines
+// at once (Lazy SMP): each worker runs its own iterative deepening search on a copy
+// of the game, and they share a transposition table, so the results found by one
+// speed up the others. The workers search plies that look equally good in different
+// orders, so they tend to explore different parts of the tree.
+//
+// It stops when the DepthLimit is reached, or when the TimeLimit has elapsed.
+// At least one of them should be given; with neither, MinTimeLimit is used.
+type ParallelSearcher struct {
+	ToMax c.Color
+	Heuristic
+	// Zero means no limit
+	DepthLimit int
+	// Zero means no limit, otherwise kept between MinTimeLimit and MaxTimeLimit
+	TimeLimit time.Duration
+	// Number of goroutines searching, runtime.NumCPU() if zero
+	Workers int
+	// Same as in DepthLimitedSearcher
+	Table           *TranspositionTable
+	Randomize       bool
+	QuiescenceDepth int
+	Tablebase       *tablebase.Tablebase
+	// Optional, receives the result of each depth searched, deeper than the ones before.
+	// Calls don't overlap, but may come from different goroutines.
+	Progress ProgressFunc
+}
+
+var _ Analyzer = ParallelSearcher{}
+
+// Result of the deepest search finished by the workers so far
+type parallelResult struct {
+	mu    sync.Mutex
+	depth int
+	value float64
+	ply   c.Ply
+	// called with the lock held when the result is updated
+	progress func(depth int, value float64, ply c.Ply)
+}
+
+func (r *parallelResult) update(depth int, value float64, ply c.Ply) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if depth > r.depth {
+		r.depth, r.value, r.ply = depth, value, ply
+		if r.progress != nil {
+			r.progress(depth, value, ply)
+		}
+	}
+}
+
+func (r *parallelResult) get() (int, float64, c.Ply) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.depth, r.value, r.ply
+}
+
+func (s ParallelSearcher) timeLimit() time.Duration {
+	if s.TimeLimit == 0 && s.DepthLimit > 0 {
+		return 0
+	}
+	return clampTimeLimit(s.TimeLimit)
+}
+
+func (s ParallelSearcher) Search(g *c.Game) c.Ply {
+	return s.SearchContext(context.Background(), g)
+}
+
+// As in TimeLimitedSearcher, the time limit is one more deadline for the context,
+// and the ply is the one of the deepest search finished, or nil if none finished in time
+func (s ParallelSearcher) SearchContext(ctx context.Context, g *c.Game) c.Ply {
+	return s.Analyze(ctx, g).Ply
+}
+
+// The number of nodes is the sum of the nodes searched by all workers
+func (s ParallelSearcher) Analyze(parent context.Context, g *c.Game) SearchResult {
+	start := time.Now()
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var ctx context.Context
+	var stop context.CancelFunc
+	if tlim := s.timeLimit(); tlim > 0 {
+		ctx, stop = context.WithTimeout(parent, tlim)
+	} else {
+		ctx, stop = context.WithCancel(parent)
+	}
+	defer stop()
+	stopTime, _ := ctx.Deadline()
+
+	tt := tableOrNew(s.Table)
+	var result parallelResult
+	var wg sync.WaitGroup
+	contexts := make([]searchContext, workers)
+	for i := range contexts {
+		contexts[i] = searchContext{
+			toMax:      s.ToMax,
+			h:          s.Heuristic,
+			closer:     ctx.Done(),
+			tt:         tt,
+			variantKey: variantKey(g.Variant()),
+			// the helpers always break ties randomly, so they don't all search the same plies first
+			ord:   newOrderer(s.Randomize || i > 0),
+			stats: new(searchStats),
+
+			quiescenceDepth: quiescenceDepthOrDefault(s.QuiescenceDepth),
+			tb:              s.Tablebase,
+		}
+	}
+
+	// the main worker's result, with the nodes of all of them
+	report := func(g *c.Game, ply c.Ply, value float64, depth int) SearchResult {
+		r := contexts[0].result(g, ply, value, depth, start)
+		for _, sc := range contexts[1:] {
+			r.Nodes += sc.stats.count()
+		}
+		return r
+	}
+
+	if s.Progress != nil {
+		root := g.Copy()
+		result.progress = func(depth int, value float64, ply c.Ply) {
+			s.Progress(report(root, ply, value, depth))
+		}
+	}
+
+	for i := range contexts {
+		// half of the helpers start one ply deeper, also to spread the work
+		depth := 1 + i%2
+		if s.DepthLimit > 0 && depth > s.DepthLimit {
+			depth = s.DepthLimit
+		}
+
+		wg.Add(1)
+		go func(sc searchContext, g *c.Game, depth int, main bool) {
+			defer wg.Done()
+			s.work(sc, g, depth, main, stopTime, stop, &result)
+		}(contexts[i], g.Copy(), depth, i == 0)
+	}
+
+	wg.Wait()
+
+	depth, value, ply := result.get()
+	return report(g, ply, value, depth)
+}
+
+func (s ParallelSearcher) work(
+	ctx searchContext,
+	g *c.Game,
+	startDepth int,
+	main bool,
+	stopTime time.Time,
+	stop context.CancelFunc,
+	result *parallelResult,
+) {
+	var ply c.Ply
+	finished := 0
+	for dlim := startDepth; s.DepthLimit <= 0 || dlim <= s.DepthLimit; dlim++ {
+		searchStart := time.Now()
+		ctx.pv = ply
+		value, ply0 := ctx.search(g, dlim, 0, math.Inf(-1), math.Inf(1))
+		// same as in TimeLimitedSearcher, interrupted searches can't be trusted
+		if ctx.closed() {
+			return
+		}
+		ply = ply0
+		finished = dlim
+		result.update(dlim, value, ply)
+		if mateIn(value) != 0 {
+			stop()
+			return
+		}
+
+		if main && !stopTime.IsZero() && time.Since(searchStart) >= time.Until(stopTime) {
+			break
+		}
+		if ply == nil {
+			// the game is over, there's nothing to search
+			break
+		}
+	}
+	// any worker finishing the depth limit ends the search, and the main one decides when there's not enough time left
+	if main || (s.DepthLimit > 0 && finished == s.DepthLimit) {
+		stop()
+	}
+}
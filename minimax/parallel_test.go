@@ -0,0 +1,138 @@
+package minimax
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func assertLegalPly(t *testing.T, g *c.Game, ply c.Ply) {
+	t.Helper()
+	for _, p := range g.Plies() {
+		if p.Equals(ply) {
+			return
+		}
+	}
+	t.Errorf("searcher returned a ply that is not legal: %v", ply)
+}
+
+func TestParallelSearcherDepthLimited(t *testing.T) {
+	g := c.NewGame()
+	before := g.Copy()
+	s := ParallelSearcher{
+		ToMax:      c.WhiteColor,
+		Heuristic:  WeightedCountHeuristic,
+		DepthLimit: 5,
+		Workers:    4,
+	}
+	ply := s.Search(g)
+	assertLegalPly(t, g, ply)
+	if !g.Equals(before) {
+		t.Errorf("the game searched should be left as it was")
+	}
+}
+
+func TestParallelSearcherDepthOne(t *testing.T) {
+	g := c.NewGame()
+	s := ParallelSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 1, Workers: 4}
+	// the helpers that would start at depth 2 mustn't stop the others before depth 1 is done
+	for i := 0; i < 50; i++ {
+		res := s.Analyze(context.Background(), g)
+		if res.Depth != 1 {
+			t.Fatalf("expected depth 1, got %d", res.Depth)
+		}
+		assertLegalPly(t, g, res.Ply)
+	}
+}
+
+func TestParallelSearcherCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := ParallelSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 6, Workers: 2}
+	if ply := s.SearchContext(ctx, c.NewGame()); ply != nil {
+		t.Errorf("expected no ply from a cancelled search, got %v", ply)
+	}
+}
+
+func TestParallelSearcherTimeLimited(t *testing.T) {
+	g := c.NewGame()
+	s := ParallelSearcher{
+		ToMax:     c.BlackColor,
+		Heuristic: UnweightedCountHeuristic,
+		TimeLimit: 100 * time.Millisecond,
+		Table:     NewTranspositionTable(1 << 12),
+	}
+	for i := 0; i < 6 && !g.Result().Over(); i++ {
+		s.ToMax = g.ToPlay()
+		start := time.Now()
+		ply := s.Search(g)
+		if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+			t.Errorf("parallel searcher took too long: %v", elapsed)
+		}
+		assertLegalPly(t, g, ply)
+		if _, err := g.DoPly(ply); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParallelSearcherAgreesOnValue(t *testing.T) {
+	b := c.DecodeBoard(`
+	  .x.x.x.x
+		x.x.x.x.
+		...x.x.x
+		..x
+		...o
+		o.o...o.
+		.o.o.o.o
+		o.o.o.o.
+	`)
+	g := c.NewCustomGame(c.BrazilianVariant, b, c.WhiteColor)
+
+	ctx := searchContext{toMax: c.WhiteColor, h: WeightedCountHeuristic, quiescenceDepth: DefaultQuiescenceDepth}
+	want, _ := ctx.search(g, 4, 0, -winValue*2, winValue*2)
+
+	s := ParallelSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 4, Workers: 3}
+	ply := s.Search(g)
+	assertLegalPly(t, g, ply)
+
+	undo, _ := g.DoPly(ply)
+	got, _ := ctx.search(g, 3, 1, -winValue*2, winValue*2)
+	g.UndoPly(undo)
+	if got != want {
+		t.Errorf("expected the ply found to have the best value %g, got %g", want, got)
+	}
+}
+
+func TestParallelSearcherGameOver(t *testing.T) {
+	b := c.DecodeBoard(`
+	  .
+		.
+		.
+		...o
+	`)
+	g := c.NewCustomGame(c.BrazilianVariant, b, c.BlackColor)
+	s := ParallelSearcher{ToMax: c.BlackColor, Heuristic: WeightedCountHeuristic, Workers: 2, TimeLimit: MinTimeLimit}
+	if ply := s.Search(g); ply != nil {
+		t.Errorf("expected no ply for a game that is over, got %v", ply)
+	}
+}
+
+func TestParallelSearcherTimeLimit(t *testing.T) {
+	tests := []struct {
+		s    ParallelSearcher
+		want time.Duration
+	}{
+		{ParallelSearcher{}, MinTimeLimit},
+		{ParallelSearcher{DepthLimit: 3}, 0},
+		{ParallelSearcher{DepthLimit: 3, TimeLimit: time.Second}, time.Second},
+		{ParallelSearcher{TimeLimit: time.Hour}, MaxTimeLimit},
+	}
+	for _, test := range tests {
+		if got := test.s.timeLimit(); got != test.want {
+			t.Errorf("%+v: wanted time limit %v got %v", test.s, test.want, got)
+		}
+	}
+}
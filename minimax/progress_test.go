@@ -0,0 +1,71 @@
+package minimax
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func assertProgress(t *testing.T, name string, updates []SearchResult, final SearchResult) {
+	t.Helper()
+	if len(updates) == 0 {
+		t.Fatalf("%s: expected progress updates", name)
+	}
+	for i, r := range updates {
+		if r.Ply == nil || len(r.PV) == 0 || r.Nodes <= 0 {
+			t.Errorf("%s: incomplete update %+v", name, r)
+		}
+		if i > 0 && r.Depth <= updates[i-1].Depth {
+			t.Errorf("%s: expected increasing depths, got %d after %d", name, r.Depth, updates[i-1].Depth)
+		}
+		if i > 0 && r.Nodes < updates[i-1].Nodes {
+			t.Errorf("%s: expected the node count to increase", name)
+		}
+	}
+	last := updates[len(updates)-1]
+	if last.Depth != final.Depth || !last.Ply.Equals(final.Ply) {
+		t.Errorf("%s: the last update (depth %d) should match the result (depth %d)", name, last.Depth, final.Depth)
+	}
+}
+
+func TestTimeLimitedProgress(t *testing.T) {
+	var updates []SearchResult
+	s := TimeLimitedSearcher{
+		ToMax:     c.WhiteColor,
+		Heuristic: WeightedCountHeuristic,
+		TimeLimit: MinTimeLimit,
+		Progress: func(r SearchResult) {
+			updates = append(updates, r)
+		},
+	}
+	r := s.Analyze(context.Background(), c.NewGame())
+	assertProgress(t, "time limited", updates, r)
+	if updates[0].Depth != 1 {
+		t.Errorf("expected the first update to be for depth 1, got %d", updates[0].Depth)
+	}
+}
+
+func TestParallelProgress(t *testing.T) {
+	var mu sync.Mutex
+	var updates []SearchResult
+	s := ParallelSearcher{
+		ToMax:      c.WhiteColor,
+		Heuristic:  WeightedCountHeuristic,
+		DepthLimit: 5,
+		Workers:    3,
+		Progress: func(r SearchResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates = append(updates, r)
+		},
+	}
+	r := s.Analyze(context.Background(), c.NewGame())
+	mu.Lock()
+	defer mu.Unlock()
+	assertProgress(t, "parallel", updates, r)
+	if r.Depth != 5 {
+		t.Errorf("expected to reach depth 5, got %d", r.Depth)
+	}
+}
@@ -0,0 +1,94 @@
+package minimax
+
+import (
+	"math"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+const DefaultQuiescenceDepth = 8
+
+func quiescenceDepthOrDefault(depth int) int {
+	if depth == 0 {
+		return DefaultQuiescenceDepth
+	}
+	if depth < 0 {
+		return 0
+	}
+	return depth
+}
+
+func capturePlies(plies []c.Ply) []c.Ply {
+	var captures []c.Ply
+	for _, p := range plies {
+		if captureCount(p) > 0 {
+			captures = append(captures, p)
+		}
+	}
+	return captures
+}
+
+// Evaluates the position once the depth limit is reached, but only after
+// playing out the captures available, since the heuristic can't be trusted
+// in the middle of a trade. The player to move may "stand pat" (keep the
+// heuristic value instead of capturing) only if captures aren't mandatory.
+func (ctx searchContext) quiesce(g *c.Game, depthLeft int, height int, alpha float64, beta float64) float64 {
+	ctx.stats.add()
+	if res := g.Result(); res.Over() {
+		return ctx.resultValue(res, height)
+	}
+
+	standPat := ctx.h(g.Board(), ctx.toMax)
+	if depthLeft <= 0 || ctx.closed() {
+		return standPat
+	}
+
+	plies := g.Plies()
+	captures := capturePlies(plies)
+	if len(captures) == 0 {
+		return standPat
+	}
+
+	maximizeTurn := g.ToPlay() == ctx.toMax
+
+	value := math.Inf(1)
+	if maximizeTurn {
+		value = math.Inf(-1)
+	}
+	if len(captures) < len(plies) {
+		value = standPat
+		if maximizeTurn {
+			if value >= beta {
+				return value
+			}
+			alpha = math.Max(alpha, value)
+		} else {
+			if value <= alpha {
+				return value
+			}
+			beta = math.Min(beta, value)
+		}
+	}
+
+	for _, p := range ctx.ord.order(captures, nil, height, g.ToPlay()) {
+		undoInfo, _ := g.DoPly(p)
+		subValue := ctx.quiesce(g, depthLeft-1, height+1, alpha, beta)
+		g.UndoPly(undoInfo)
+
+		if maximizeTurn {
+			value = math.Max(value, subValue)
+			alpha = math.Max(alpha, value)
+			if value >= beta {
+				break
+			}
+		} else {
+			value = math.Min(value, subValue)
+			beta = math.Min(beta, value)
+			if value <= alpha {
+				break
+			}
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,81 @@
+package minimax
+
+import (
+	"math"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func TestQuiescencePendingCapture(t *testing.T) {
+	b := c.DecodeBoard(`
+	  .x
+		.
+		.
+		..x
+		...o
+		.
+		.
+		o
+	`)
+	t.Log("\n" + b.String())
+	g := c.NewCustomGame(c.BrazilianVariant, b, c.BlackColor)
+
+	plain := searchContext{toMax: c.WhiteColor, h: WeightedCountHeuristic}
+	quiet := plain
+	quiet.quiescenceDepth = DefaultQuiescenceDepth
+
+	// black is about to capture, but without the quiescence search the material is even
+	if v, _ := plain.search(g, 0, 0, math.Inf(-1), math.Inf(1)); v != 0 {
+		t.Errorf("wanted 0 without quiescence, got %g", v)
+	}
+	if v, _ := quiet.search(g, 0, 0, math.Inf(-1), math.Inf(1)); v != -1 {
+		t.Errorf("wanted -1 with quiescence, got %g", v)
+	}
+}
+
+func TestQuiescenceStandPat(t *testing.T) {
+	// whichever pawn white captures with, black captures two back
+	b := c.DecodeBoard(`
+	  .
+		x.....x
+		.x...x
+		.
+		...x
+		..o.o
+		.......o
+	`)
+	t.Log("\n" + b.String())
+
+	ctx := searchContext{
+		toMax:           c.WhiteColor,
+		h:               WeightedCountHeuristic,
+		quiescenceDepth: DefaultQuiescenceDepth,
+		ord:             newOrderer(false),
+	}
+
+	mandatory := c.NewCustomGame(c.AmericanVariant, b.Copy(), c.WhiteColor)
+	if v := ctx.quiesce(mandatory, ctx.quiescenceDepth, 0, math.Inf(-1), math.Inf(1)); v != -3 {
+		t.Errorf("wanted -3 when white has to capture, got %g", v)
+	}
+
+	v := c.AmericanVariant
+	v.CaptureRule = c.CapturesNotMandatory
+	optional := c.NewCustomGame(v, b.Copy(), c.WhiteColor)
+	if v := ctx.quiesce(optional, ctx.quiescenceDepth, 0, math.Inf(-1), math.Inf(1)); v != -2 {
+		t.Errorf("wanted -2 when white can choose not to capture, got %g", v)
+	}
+
+	// with no depth left the heuristic is used as it is
+	if v := ctx.quiesce(mandatory, 0, 0, math.Inf(-1), math.Inf(1)); v != -2 {
+		t.Errorf("wanted -2 with no depth left, got %g", v)
+	}
+}
+
+func TestQuiescenceDepthOrDefault(t *testing.T) {
+	if quiescenceDepthOrDefault(0) != DefaultQuiescenceDepth ||
+		quiescenceDepthOrDefault(-1) != 0 ||
+		quiescenceDepthOrDefault(3) != 3 {
+		t.Errorf("wrong quiescence depths")
+	}
+}
@@ -0,0 +1,154 @@
+package minimax
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// A SearchResult is what a search found out about a game, besides the best ply
+type SearchResult struct {
+	// Best ply found, nil if the game is over or the search didn't finish any iteration
+	Ply c.Ply
+	// Value of the game for the player to maximize
+	Value float64
+	// When the search found a forced end to the game, the number of plies until it ends:
+	// positive if the player to maximize wins, negative if it loses. Zero otherwise.
+	MateIn int
+	// Principal variation: the plies both players are expected to play from the game searched,
+	// starting with Ply. May be shorter than Depth.
+	PV []c.Ply
+	// Depth of the deepest search finished
+	Depth int
+	// Number of positions visited (including the ones in unfinished iterations)
+	Nodes   int64
+	Elapsed time.Duration
+}
+
+// Nodes searched per second
+func (r SearchResult) NPS() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Nodes) / r.Elapsed.Seconds()
+}
+
+// Called by searchers that use iterative deepening after each depth they finish searching,
+// on the goroutine doing the search, so it should return quickly
+type ProgressFunc func(SearchResult)
+
+// An Analyzer is a Searcher that can also tell more about its search than the best ply
+type Analyzer interface {
+	Searcher
+	Analyze(ctx context.Context, g *c.Game) SearchResult
+}
+
+// Values above mateThreshold (or below -mateThreshold) are wins (or losses):
+// winValue minus the number of plies to reach it, so that the sooner wins
+// (and the later losses) are preferred
+const (
+	maxMateHeight = 1000
+	mateThreshold = winValue - maxMateHeight
+)
+
+func mateIn(value float64) int {
+	if value >= mateThreshold {
+		return int(winValue - value)
+	}
+	if value <= -mateThreshold {
+		return -int(value - lossValue)
+	}
+	return 0
+}
+
+// Mate values are stored in the transposition table relative to the position
+// they're stored for, not to the root of the search, since the same position
+// may be reached at different heights
+
+func toTableValue(value float64, height int) float64 {
+	if value >= mateThreshold {
+		return value + float64(height)
+	}
+	if value <= -mateThreshold {
+		return value - float64(height)
+	}
+	return value
+}
+
+func fromTableValue(value float64, height int) float64 {
+	if value >= mateThreshold {
+		return value - float64(height)
+	}
+	if value <= -mateThreshold {
+		return value + float64(height)
+	}
+	return value
+}
+
+// Nodes are counted atomically so the progress of parallel searches can be reported while they run
+type searchStats struct {
+	nodes int64
+}
+
+func (s *searchStats) add() {
+	if s != nil {
+		atomic.AddInt64(&s.nodes, 1)
+	}
+}
+
+func (s *searchStats) count() int64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&s.nodes)
+}
+
+// Follows the best plies stored in the transposition table, starting with the given one
+func (ctx searchContext) principalVariation(g *c.Game, first c.Ply, maxLength int) []c.Ply {
+	if first == nil {
+		return nil
+	}
+	g = g.Copy()
+	pv := []c.Ply{first}
+	seen := map[uint64]bool{g.Hash(): true}
+	ply := first
+	for {
+		if _, err := g.DoPly(ply); err != nil {
+			break
+		}
+		if seen[g.Hash()] || len(pv) >= maxLength || ctx.tt == nil || g.Result().Over() {
+			break
+		}
+		seen[g.Hash()] = true
+		e, ok := ctx.tt.probe(ctx.ttKey(g))
+		if !ok || e.ply == nil || !isLegal(g, e.ply) {
+			break
+		}
+		ply = e.ply
+		pv = append(pv, ply)
+	}
+	return pv
+}
+
+func isLegal(g *c.Game, ply c.Ply) bool {
+	for _, p := range g.Plies() {
+		if p.Equals(ply) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ctx searchContext) result(g *c.Game, ply c.Ply, value float64, depth int, start time.Time) SearchResult {
+	return SearchResult{
+		Ply:     ply,
+		Value:   value,
+		MateIn:  mateIn(value),
+		PV:      ctx.principalVariation(g, ply, depth),
+		Depth:   depth,
+		Nodes:   ctx.stats.count(),
+		Elapsed: time.Since(start),
+	}
+}
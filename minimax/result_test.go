@@ -0,0 +1,120 @@
+package minimax
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func TestMateValues(t *testing.T) {
+	tests := []struct {
+		value  float64
+		mateIn int
+	}{
+		{winValue - 3, 3},
+		{lossValue + 4, -4},
+		// the game is already over
+		{winValue, 0},
+		{12, 0},
+		{-12, 0},
+	}
+	for _, test := range tests {
+		if got := mateIn(test.value); got != test.mateIn {
+			t.Errorf("value %g: wanted mate in %d got %d", test.value, test.mateIn, got)
+		}
+		for _, height := range []int{0, 1, 7} {
+			if got := fromTableValue(toTableValue(test.value, height), height); got != test.value {
+				t.Errorf("value %g at height %d: got %g back from the table", test.value, height, got)
+			}
+		}
+	}
+
+	// a win 5 plies from the root found at height 2 is a win in 3 from there
+	if got := toTableValue(winValue-5, 2); got != winValue-3 {
+		t.Errorf("wrong value stored in the table %g", got)
+	}
+	if got := toTableValue(3, 2); got != 3 {
+		t.Errorf("values that aren't mates are stored as they are, got %g", got)
+	}
+}
+
+func TestAnalyzeMate(t *testing.T) {
+	b := c.DecodeBoard(`
+	  .
+		.
+		.
+		.
+		...x
+		..o
+	`)
+	g := c.NewCustomGame(c.BrazilianVariant, b, c.WhiteColor)
+	capture := c.Ply{
+		c.MakeMoveInstruction(5, 2, 3, 4),
+		c.MakeCaptureInstruction(4, 3, c.BlackColor, c.PawnKind),
+	}
+
+	analyzers := []Analyzer{
+		DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 3},
+		TimeLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, TimeLimit: MaxTimeLimit},
+		ParallelSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, TimeLimit: MaxTimeLimit, Workers: 2},
+	}
+	for _, a := range analyzers {
+		start := time.Now()
+		r := a.Analyze(context.Background(), g)
+		if !r.Ply.Equals(capture) {
+			t.Errorf("%T: expected the capture, got %v", a, r.Ply)
+		}
+		if r.MateIn != 1 || r.Value != winValue-1 {
+			t.Errorf("%T: expected mate in 1, got %d (value %g)", a, r.MateIn, r.Value)
+		}
+		if len(r.PV) != 1 || !r.PV[0].Equals(capture) {
+			t.Errorf("%T: wrong principal variation %v", a, r.PV)
+		}
+		if r.Nodes <= 0 || r.Depth <= 0 {
+			t.Errorf("%T: expected some nodes and depth, got %d and %d", a, r.Nodes, r.Depth)
+		}
+		// the end of the game is known, no need to use all the time available
+		if time.Since(start) > time.Second {
+			t.Errorf("%T: took too long to find a mate in 1", a)
+		}
+	}
+
+	s := DepthLimitedSearcher{ToMax: c.BlackColor, Heuristic: WeightedCountHeuristic, DepthLimit: 3}
+	if r := s.Analyze(context.Background(), g); r.MateIn != -1 {
+		t.Errorf("expected black to be mated in 1, got %d", r.MateIn)
+	}
+}
+
+func TestAnalyzePrincipalVariation(t *testing.T) {
+	g := c.NewGame()
+	s := DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 5}
+	r := s.Analyze(context.Background(), g)
+	if r.Depth != 5 || r.Elapsed <= 0 || r.NPS() <= 0 {
+		t.Errorf("wrong statistics %+v", r)
+	}
+	if len(r.PV) == 0 || len(r.PV) > 5 || !r.PV[0].Equals(r.Ply) {
+		t.Fatalf("wrong principal variation %v for ply %v", r.PV, r.Ply)
+	}
+	h := g.Copy()
+	for _, p := range r.PV {
+		assertLegalPly(t, h, p)
+		if _, err := h.DoPly(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyzeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 5}
+	r := s.Analyze(ctx, c.NewGame())
+	if r.Ply != nil || r.Depth != 0 || r.PV != nil {
+		t.Errorf("expected an empty result for a cancelled search, got %+v", r)
+	}
+	if (SearchResult{}).NPS() != 0 {
+		t.Errorf("expected 0 nodes per second without time elapsed")
+	}
+}
@@ -0,0 +1,149 @@
+package minimax
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Bound tells how the value stored in a transposition table entry relates to the actual value of the position
+type Bound byte
+
+const (
+	// the value is exact
+	ExactBound = Bound(iota)
+	// the actual value is at least the value stored (the search failed high)
+	LowerBound
+	// the actual value is at most the value stored (the search failed low)
+	UpperBound
+)
+
+func (b Bound) String() string {
+	switch b {
+	case ExactBound:
+		return "exact"
+	case LowerBound:
+		return "lower"
+	case UpperBound:
+		return "upper"
+	default:
+		return "invalid"
+	}
+}
+
+const DefaultTranspositionTableSize = 1 << 16
+
+// Xor'ed into the keys of the entries when the player to maximize is black,
+// so that searches for both players can share a table
+const blackToMaxKey = 0x5bd1e9955bd1e995
+
+type ttEntry struct {
+	key   uint64
+	value float64
+	ply   c.Ply
+	depth int16
+	// search in which the entry was stored, older entries are always replaced
+	age   uint16
+	bound Bound
+	used  bool
+}
+
+// A TranspositionTable stores the results of searches by position, so positions
+// reached again through a different sequence of plies don't have to be searched again.
+// It has a fixed number of entries; when two positions fall in the same entry,
+// the one searched deeper is kept, unless the other was stored by an older search.
+// It's meant to be used by searchers with the same heuristic.
+// Probing and storing entries is safe for concurrent use, so parallel searches can share a table.
+type TranspositionTable struct {
+	entries []ttEntry
+	mask    uint64
+	age     uint16
+	// entry i is guarded by locks[i%ttLockCount]
+	locks [ttLockCount]sync.Mutex
+}
+
+const ttLockCount = 256
+
+// Creates a table with at least the given number of entries (rounded up to a power of two)
+func NewTranspositionTable(size int) *TranspositionTable {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	return &TranspositionTable{
+		entries: make([]ttEntry, n),
+		mask:    uint64(n - 1),
+	}
+}
+
+func (tt *TranspositionTable) Size() int {
+	return len(tt.entries)
+}
+
+// Marks the start of a new search, so the entries stored by the previous ones
+// give way to the new ones. Not safe to call during a search.
+func (tt *TranspositionTable) newSearch() {
+	tt.age++
+}
+
+// Xor'ed into the keys of the entries, so that searches of games of different variants
+// can share a table: the same position may have other plies and values under other rules
+func variantKey(v c.Variant) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%+v", v)
+	return h.Sum64()
+}
+
+func (ctx searchContext) ttKey(g *c.Game) uint64 {
+	key := g.Hash() ^ ctx.variantKey
+	if ctx.toMax == c.BlackColor {
+		key ^= blackToMaxKey
+	}
+	return key
+}
+
+func (tt *TranspositionTable) lock(i uint64) *sync.Mutex {
+	return &tt.locks[i%ttLockCount]
+}
+
+func (tt *TranspositionTable) probe(key uint64) (ttEntry, bool) {
+	i := key & tt.mask
+	mu := tt.lock(i)
+	mu.Lock()
+	e := tt.entries[i]
+	mu.Unlock()
+	if !e.used || e.key != key {
+		return ttEntry{}, false
+	}
+	return e, true
+}
+
+func (tt *TranspositionTable) store(key uint64, depth int, value float64, bound Bound, ply c.Ply) {
+	i := key & tt.mask
+	mu := tt.lock(i)
+	mu.Lock()
+	defer mu.Unlock()
+	e := &tt.entries[i]
+	if e.used && e.key != key && e.age == tt.age && int(e.depth) > depth {
+		return
+	}
+	*e = ttEntry{
+		key:   key,
+		value: value,
+		ply:   ply,
+		depth: int16(depth),
+		age:   tt.age,
+		bound: bound,
+		used:  true,
+	}
+}
+
+// Removes all entries from the table. Not safe to call during a search.
+func (tt *TranspositionTable) Clear() {
+	for i := range tt.entries {
+		tt.entries[i] = ttEntry{}
+	}
+	tt.age = 0
+}
@@ -0,0 +1,143 @@
+package minimax
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func TestNewTranspositionTable(t *testing.T) {
+	for _, test := range [][2]int{{0, 1}, {1, 1}, {3, 4}, {1000, 1024}, {1024, 1024}} {
+		if got := NewTranspositionTable(test[0]).Size(); got != test[1] {
+			t.Errorf("table of size %d: wanted %d entries got %d", test[0], test[1], got)
+		}
+	}
+}
+
+func TestTranspositionTableReplacement(t *testing.T) {
+	tt := NewTranspositionTable(4)
+	ply := c.Ply{c.MakeMoveInstruction(5, 0, 4, 1)}
+
+	if _, ok := tt.probe(1); ok {
+		t.Errorf("expected empty table")
+	}
+
+	tt.store(1, 5, 10, ExactBound, ply)
+	e, ok := tt.probe(1)
+	if !ok || e.depth != 5 || e.value != 10 || e.bound != ExactBound || !e.ply.Equals(ply) {
+		t.Errorf("wrong entry %+v", e)
+	}
+
+	// same slot, shallower search: kept the deeper one
+	tt.store(5, 3, 20, LowerBound, nil)
+	if _, ok := tt.probe(5); ok {
+		t.Errorf("expected the deeper entry to be kept")
+	}
+	// same position: always replaced
+	tt.store(1, 2, 30, UpperBound, nil)
+	if e, ok := tt.probe(1); !ok || e.value != 30 {
+		t.Errorf("expected the entry for the same position to be replaced")
+	}
+
+	// entries from older searches give way
+	tt.store(1, 9, 40, ExactBound, nil)
+	tt.newSearch()
+	tt.store(5, 1, 50, ExactBound, nil)
+	if e, ok := tt.probe(5); !ok || e.value != 50 {
+		t.Errorf("expected the older entry to be replaced")
+	}
+	if _, ok := tt.probe(1); ok {
+		t.Errorf("expected the older entry to be gone")
+	}
+
+	tt.Clear()
+	if _, ok := tt.probe(5); ok {
+		t.Errorf("expected empty table after clearing it")
+	}
+}
+
+func TestBoundString(t *testing.T) {
+	if ExactBound.String() != "exact" || LowerBound.String() != "lower" ||
+		UpperBound.String() != "upper" || Bound(42).String() != "invalid" {
+		t.Errorf("wrong bound strings")
+	}
+}
+
+func TestTableKeepsSearchValue(t *testing.T) {
+	g := c.NewGame()
+	for i := 0; i < 6; i++ {
+		for _, toMax := range []c.Color{c.WhiteColor, c.BlackColor} {
+			plain := searchContext{toMax: toMax, h: WeightedCountHeuristic}
+			withTable := plain
+			withTable.tt = NewTranspositionTable(1 << 12)
+
+			want, _ := plain.search(g, 4, 0, math.Inf(-1), math.Inf(1))
+			got, ply := withTable.search(g, 4, 0, math.Inf(-1), math.Inf(1))
+			if got != want {
+				t.Errorf("ply %d: wanted value %g got %g with the table", i, want, got)
+			}
+			if ply == nil {
+				t.Errorf("ply %d: expected a ply", i)
+			}
+
+			// searching again should hit the table at the root
+			again, _ := withTable.search(g, 4, 0, math.Inf(-1), math.Inf(1))
+			if again != got {
+				t.Errorf("ply %d: wanted value %g got %g searching again", i, got, again)
+			}
+		}
+
+		ps := g.Plies()
+		if _, err := g.DoPly(ps[rand.Intn(len(ps))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSearchersShareTable(t *testing.T) {
+	tt := NewTranspositionTable(1 << 12)
+	g := c.NewGame()
+	white := DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 4, Table: tt}
+	black := DepthLimitedSearcher{ToMax: c.BlackColor, Heuristic: WeightedCountHeuristic, DepthLimit: 4, Table: tt}
+	for i := 0; i < 10 && !g.Result().Over(); i++ {
+		s := white
+		if g.ToPlay() == c.BlackColor {
+			s = black
+		}
+		ply := s.Search(g)
+		legal := false
+		for _, p := range g.Plies() {
+			legal = legal || p.Equals(ply)
+		}
+		if !legal {
+			t.Fatalf("searcher returned a ply that is not legal: %v", ply)
+		}
+		if _, err := g.DoPly(ply); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTableKeepsVariantsApart(t *testing.T) {
+	tt := NewTranspositionTable(1 << 12)
+	american := c.NewCustomGame(c.AmericanVariant, nil, c.WhiteColor)
+	brazilian := c.NewCustomGame(c.BrazilianVariant, nil, c.WhiteColor)
+	if american.Hash() != brazilian.Hash() {
+		t.Fatalf("expected the same position in both games")
+	}
+
+	// an entry of the american game, deep enough to be trusted, with a ply legal in neither
+	s := DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: WeightedCountHeuristic, DepthLimit: 2, Table: tt}
+	bogus := c.Ply{c.MakeMoveInstruction(0, 0, 1, 1)}
+	tt.store(s.newContext(context.Background(), american).ttKey(american), 10, 0, ExactBound, bogus)
+
+	if ply := s.Search(american); !ply.Equals(bogus) {
+		t.Errorf("expected the american search to use the entry, got %v", ply)
+	}
+	if ply := s.Search(brazilian); ply.Equals(bogus) || !isLegal(brazilian, ply) {
+		t.Errorf("expected the brazilian search not to use the entry of the american game, got %v", ply)
+	}
+}
@@ -0,0 +1,432 @@
+package pdn
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	WhiteWinResult = "2-0"
+	BlackWinResult = "0-2"
+	DrawResultText = "1-1"
+	UnknownResult  = "*"
+)
+
+// Results as they appear in PDN files, always from white's point of view
+// (international style 2-0, 0-2, 1-1 and english style 1-0, 0-1, 1/2-1/2)
+var results = map[string]string{
+	"2-0":     WhiteWinResult,
+	"1-0":     WhiteWinResult,
+	"0-2":     BlackWinResult,
+	"0-1":     BlackWinResult,
+	"1-1":     DrawResultText,
+	"1/2-1/2": DrawResultText,
+	"0-0":     UnknownResult,
+	"*":       UnknownResult,
+}
+
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// A Move is a move as written in PDN, a list of square numbers:
+// the origin, the destination and optionally the squares in between
+// (only needed to disambiguate capture paths)
+type Move struct {
+	Squares []int
+	Capture bool
+}
+
+func (m Move) String() string {
+	sep := "-"
+	if m.Capture {
+		sep = "x"
+	}
+	ss := make([]string, len(m.Squares))
+	for i, n := range m.Squares {
+		ss[i] = strconv.Itoa(n)
+	}
+	return strings.Join(ss, sep)
+}
+
+func ParseMove(s string) (Move, error) {
+	m, err := parseMove(s)
+	if err != nil {
+		return m, fmt.Errorf("pdn: %w", err)
+	}
+	return m, nil
+}
+
+func parseMove(s string) (Move, error) {
+	var m Move
+	sep := "-"
+	if strings.ContainsAny(s, "x:") {
+		m.Capture = true
+		sep = "x"
+		s = strings.ReplaceAll(s, ":", "x")
+	}
+	parts := strings.Split(s, sep)
+	if len(parts) < 2 {
+		return m, fmt.Errorf("invalid move %q", s)
+	}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 {
+			return m, fmt.Errorf("invalid square %q in move %q", part, s)
+		}
+		m.Squares = append(m.Squares, n)
+	}
+	return m, nil
+}
+
+// A Game is a single game of a PDN file
+type Game struct {
+	Tags   []Tag
+	Moves  []Move
+	Result string
+}
+
+func (g *Game) Tag(name string) (string, bool) {
+	for _, t := range g.Tags {
+		if t.Name == name {
+			return t.Value, true
+		}
+	}
+	return "", false
+}
+
+func (g *Game) SetTag(name, value string) {
+	for i, t := range g.Tags {
+		if t.Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, Tag{name, value})
+}
+
+type reader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (r *reader) next() (rune, error) {
+	c, _, err := r.r.ReadRune()
+	if c == '\n' {
+		r.line++
+	}
+	return c, err
+}
+
+func (r *reader) peek() (rune, error) {
+	c, _, err := r.r.ReadRune()
+	if err != nil {
+		return c, err
+	}
+	return c, r.r.UnreadRune()
+}
+
+func (r *reader) errorf(format string, args ...any) error {
+	return fmt.Errorf("pdn: line %d: %s", r.line, fmt.Sprintf(format, args...))
+}
+
+func (r *reader) skipUntil(end rune) error {
+	for {
+		c, err := r.next()
+		if err == io.EOF {
+			return r.errorf("missing %q", end)
+		}
+		if err != nil {
+			return err
+		}
+		if c == end {
+			return nil
+		}
+	}
+}
+
+// variations can be nested
+func (r *reader) skipVariation() error {
+	depth := 1
+	for depth > 0 {
+		c, err := r.next()
+		if err == io.EOF {
+			return r.errorf("unclosed variation")
+		}
+		if err != nil {
+			return err
+		}
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '{':
+			if err := r.skipUntil('}'); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *reader) readTag() (Tag, error) {
+	var t Tag
+	var name strings.Builder
+	for {
+		c, err := r.next()
+		if err != nil {
+			return t, r.errorf("unfinished tag")
+		}
+		if unicode.IsSpace(c) {
+			if name.Len() > 0 {
+				break
+			}
+			continue
+		}
+		if c == '"' || c == ']' {
+			return t, r.errorf("tag without a name or value")
+		}
+		name.WriteRune(c)
+	}
+	t.Name = name.String()
+
+	for {
+		c, err := r.next()
+		if err != nil {
+			return t, r.errorf("unfinished tag %s", t.Name)
+		}
+		if c == '"' {
+			break
+		}
+		if !unicode.IsSpace(c) {
+			return t, r.errorf("tag %s: value must be a string", t.Name)
+		}
+	}
+
+	var value strings.Builder
+	for {
+		c, err := r.next()
+		if err != nil {
+			return t, r.errorf("unfinished tag %s", t.Name)
+		}
+		if c == '\\' {
+			if c, err = r.next(); err != nil {
+				return t, r.errorf("unfinished tag %s", t.Name)
+			}
+		} else if c == '"' {
+			break
+		}
+		value.WriteRune(c)
+	}
+	t.Value = value.String()
+
+	if err := r.skipUntil(']'); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+func isDelimiter(c rune) bool {
+	return unicode.IsSpace(c) || strings.ContainsRune("[]{}();", c)
+}
+
+func (r *reader) readWord(first rune) (string, error) {
+	var word strings.Builder
+	word.WriteRune(first)
+	for {
+		c, err := r.peek()
+		if err == io.EOF || (err == nil && isDelimiter(c)) {
+			return word.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		r.next()
+		word.WriteRune(c)
+	}
+}
+
+// Removes the move number ("12.", "12...") before a move, if any
+func stripMoveNumber(word string) string {
+	i := 0
+	for i < len(word) && word[i] >= '0' && word[i] <= '9' {
+		i++
+	}
+	if i < len(word) && word[i] == '.' {
+		return strings.TrimLeft(word[i:], ".")
+	}
+	return word
+}
+
+// Reads all games from a PDN file
+func Read(in io.Reader) ([]*Game, error) {
+	r := &reader{r: bufio.NewReader(in), line: 1}
+
+	var games []*Game
+	g := new(Game)
+	finish := func(result string) {
+		if result == "" {
+			result, _ = g.Tag("Result")
+			if _, ok := results[result]; !ok {
+				result = UnknownResult
+			}
+		}
+		g.Result = results[result]
+		games = append(games, g)
+		g = new(Game)
+	}
+
+	for {
+		c, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case unicode.IsSpace(c):
+			continue
+		case c == '[':
+			// tags after moves mean a new game started without a result after the previous one
+			if len(g.Moves) > 0 {
+				finish("")
+			}
+			t, err := r.readTag()
+			if err != nil {
+				return nil, err
+			}
+			g.Tags = append(g.Tags, t)
+		case c == '{':
+			if err := r.skipUntil('}'); err != nil {
+				return nil, err
+			}
+		case c == ';':
+			if err := r.skipUntil('\n'); err != nil && err != io.EOF {
+				return nil, err
+			}
+		case c == '(':
+			if err := r.skipVariation(); err != nil {
+				return nil, err
+			}
+		default:
+			word, err := r.readWord(c)
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(word, "$") {
+				// numeric annotation glyph
+				continue
+			}
+			if _, ok := results[word]; ok {
+				finish(word)
+				continue
+			}
+			word = strings.TrimRight(stripMoveNumber(word), "!?+*")
+			if word == "" {
+				continue
+			}
+			m, err := parseMove(word)
+			if err != nil {
+				return nil, r.errorf("%v", err)
+			}
+			g.Moves = append(g.Moves, m)
+		}
+	}
+
+	if len(g.Tags) > 0 || len(g.Moves) > 0 {
+		finish("")
+	}
+
+	return games, nil
+}
+
+func ReadString(s string) ([]*Game, error) {
+	return Read(strings.NewReader(s))
+}
+
+const lineWidth = 80
+
+func (g *Game) write(w io.Writer) error {
+	var buf bytes.Buffer
+
+	result := g.Result
+	if result == "" {
+		result = UnknownResult
+	}
+
+	for _, t := range g.Tags {
+		value := t.Value
+		if t.Name == "Result" {
+			value = result
+		}
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		value = strings.ReplaceAll(value, `"`, `\"`)
+		fmt.Fprintf(&buf, "[%s \"%s\"]\n", t.Name, value)
+	}
+	if len(g.Tags) > 0 {
+		buf.WriteByte('\n')
+	}
+
+	firstPlayer := g.firstPlayerToMove()
+	lineLen := 0
+	write := func(s string) {
+		if lineLen > 0 && lineLen+1+len(s) > lineWidth {
+			buf.WriteByte('\n')
+			lineLen = 0
+		} else if lineLen > 0 {
+			buf.WriteByte(' ')
+			lineLen++
+		}
+		buf.WriteString(s)
+		lineLen += len(s)
+	}
+
+	number := 1
+	for i, m := range g.Moves {
+		if i == 0 && !firstPlayer {
+			write(fmt.Sprintf("%d...", number))
+		}
+		if (i%2 == 0) == firstPlayer {
+			write(fmt.Sprintf("%d.", number))
+		}
+		write(m.String())
+		if (i%2 == 0) != firstPlayer {
+			number++
+		}
+	}
+	write(result)
+	buf.WriteString("\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (g *Game) String() string {
+	var buf bytes.Buffer
+	g.write(&buf)
+	return buf.String()
+}
+
+// Writes all games to a PDN file, separated by blank lines
+func Write(w io.Writer, games []*Game) error {
+	for i, g := range games {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := g.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
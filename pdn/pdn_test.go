@@ -0,0 +1,185 @@
+package pdn
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const sampleFile = `
+[Event "Club championship"]
+[White "Alice"]
+[Black "Bob \"the bot\""]
+[Result "1-0"]
+[GameType "21"]
+
+1. 11-15 24-20 {a classic} 2. 8-11 27-24 (2... 22-18 3. 15x22) 3. 3-8! $1
+22-18 4. 15x22 25x18 1-0
+
+[Event "Unfinished"]
+[GameType "21"]
+1. 11-15 ; a line comment
+23-19
+
+[Event "Capture with colon"]
+1. 32-28 19-23 2. 28x19 14x23 *
+`
+
+func TestReadFile(t *testing.T) {
+	games, err := ReadString(sampleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 3 {
+		t.Fatalf("expected 3 games, got %d", len(games))
+	}
+
+	g := games[0]
+	if v, _ := g.Tag("Black"); v != `Bob "the bot"` {
+		t.Errorf("wrong Black tag %q", v)
+	}
+	if v, ok := g.Tag("Event"); !ok || v != "Club championship" {
+		t.Errorf("wrong Event tag %q", v)
+	}
+	if _, ok := g.Tag("Site"); ok {
+		t.Errorf("game has no Site tag")
+	}
+	wantMoves := []string{"11-15", "24-20", "8-11", "27-24", "3-8", "22-18", "15x22", "25x18"}
+	if len(g.Moves) != len(wantMoves) {
+		t.Fatalf("expected moves %v, got %v", wantMoves, g.Moves)
+	}
+	for i, m := range g.Moves {
+		if m.String() != wantMoves[i] {
+			t.Errorf("move %d: wanted %v got %v", i, wantMoves[i], m)
+		}
+	}
+	if g.Result != WhiteWinResult {
+		t.Errorf("expected white to win, got %q", g.Result)
+	}
+
+	if games[1].Result != UnknownResult || len(games[1].Moves) != 2 {
+		t.Errorf("unfinished game read wrong: %v", games[1])
+	}
+	if games[2].Result != UnknownResult || len(games[2].Moves) != 4 || !games[2].Moves[2].Capture {
+		t.Errorf("third game read wrong: %v", games[2])
+	}
+}
+
+func TestReadResultTag(t *testing.T) {
+	games, err := ReadString(`[Result "0-2"] 1. 32-28`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 || games[0].Result != BlackWinResult {
+		t.Errorf("expected the result from the tag, got %v", games)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []string{
+		`[Event "unfinished`,
+		`[Event]`,
+		`[Event unquoted]`,
+		`1. 11-15 {unclosed comment`,
+		`1. 11-15 (2. 22-18`,
+		`1. 11-15 c3-d4`,
+		`1. 11`,
+		`1. 0-15`,
+		`[ "value"]`,
+	}
+	for _, test := range tests {
+		if _, err := ReadString(test); err == nil {
+			t.Errorf("expected error reading %q", test)
+		}
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	m, err := ParseMove("22x15x6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Capture || len(m.Squares) != 3 || m.Squares[0] != 22 || m.Squares[2] != 6 {
+		t.Errorf("wrong move %v", m)
+	}
+	m, err = ParseMove("9:18")
+	if err != nil || !m.Capture || m.String() != "9x18" {
+		t.Errorf("wrong move %v (%v)", m, err)
+	}
+	for _, s := range []string{"15", "a-b", "1--2", ""} {
+		if _, err := ParseMove(s); err == nil || !strings.HasPrefix(err.Error(), "pdn: ") {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestSetTag(t *testing.T) {
+	var g Game
+	g.SetTag("Event", "a")
+	g.SetTag("White", "b")
+	g.SetTag("Event", "c")
+	if len(g.Tags) != 2 || g.Tags[0].Value != "c" {
+		t.Errorf("wrong tags %v", g.Tags)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	games, err := ReadString(sampleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, games); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + buf.String())
+
+	again, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != len(games) {
+		t.Fatalf("expected %d games, got %d", len(games), len(again))
+	}
+	for i := range games {
+		if games[i].String() != again[i].String() {
+			t.Errorf("game %d changed after writing and reading:\n%v\n%v", i, games[i], again[i])
+		}
+	}
+}
+
+func TestWriteMoveNumbers(t *testing.T) {
+	g := &Game{
+		Tags:   []Tag{{"GameType", "21"}},
+		Result: DrawResultText,
+	}
+	for _, s := range []string{"11-15", "23-19", "8-11"} {
+		m, _ := ParseMove(s)
+		g.Moves = append(g.Moves, m)
+	}
+	want := "[GameType \"21\"]\n\n1. 11-15 23-19 2. 8-11 1-1\n"
+	if got := g.String(); got != want {
+		t.Errorf("wanted %q got %q", want, got)
+	}
+
+	// white to move first in american checkers, so the first move is the second of the pair
+	g.SetTag("FEN", "W:W21-32:B1-12")
+	g.Moves = g.Moves[1:2]
+	want = "[GameType \"21\"]\n[FEN \"W:W21-32:B1-12\"]\n\n1... 23-19 1-1\n"
+	if got := g.String(); got != want {
+		t.Errorf("wanted %q got %q", want, got)
+	}
+}
+
+func TestWriteWrapsLines(t *testing.T) {
+	var g Game
+	for i := 0; i < 60; i++ {
+		g.Moves = append(g.Moves, Move{Squares: []int{31, 27}})
+	}
+	for _, line := range strings.Split(g.String(), "\n") {
+		if len(line) > lineWidth {
+			t.Errorf("line longer than %d: %q", lineWidth, line)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package pdn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Values of the GameType tag
+const (
+	InternationalGameType = 20
+	AmericanGameType      = 21
+	ItalianGameType       = 22
+	RussianGameType       = 25
+	BrazilianGameType     = 26
+
+	// What the standard says games without a GameType tag are
+	defaultGameType = InternationalGameType
+)
+
+var gameTypeVariants = map[int]c.Variant{
+	InternationalGameType: c.InternationalVariant,
+	AmericanGameType:      c.AmericanVariant,
+	ItalianGameType:       c.ItalianVariant,
+	RussianGameType:       c.RussianVariant,
+	BrazilianGameType:     c.BrazilianVariant,
+}
+
+func GameTypeOf(v c.Variant) (int, bool) {
+	for gameType, w := range gameTypeVariants {
+		if w.Name == v.Name {
+			return gameType, true
+		}
+	}
+	return 0, false
+}
+
+// In american checkers the black pieces move first
+func FirstPlayer(v c.Variant) c.Color {
+	if v.Name == c.AmericanVariant.Name {
+		return c.BlackColor
+	}
+	return c.WhiteColor
+}
+
+func (g *Game) Variant() (c.Variant, error) {
+	gameType := defaultGameType
+	if s, ok := g.Tag("GameType"); ok {
+		// the game type may be followed by other comma separated fields (board size, colors etc.)
+		s, _, _ = strings.Cut(s, ",")
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return c.Variant{}, fmt.Errorf("pdn: invalid GameType %q", s)
+		}
+		gameType = n
+	}
+	v, ok := gameTypeVariants[gameType]
+	if !ok {
+		return c.Variant{}, fmt.Errorf("pdn: unsupported GameType %d", gameType)
+	}
+	return v, nil
+}
+
+// Whether the side to move at the start of the game is the one that moves first in its variant
+func (g *Game) firstPlayerToMove() bool {
+	start, err := g.Start()
+	if err != nil {
+		return true
+	}
+	return start.ToPlay() == FirstPlayer(start.Variant())
+}
+
+// Returns the game in its starting position, given by the FEN tag or
+// the initial position of its variant
+func (g *Game) Start() (*c.Game, error) {
+	v, err := g.Variant()
+	if err != nil {
+		return nil, err
+	}
+	fen, ok := g.Tag("FEN")
+	if !ok {
+		return c.NewCustomGame(v, nil, FirstPlayer(v)), nil
+	}
+	return c.NewGameFromFEN(v, fen)
+}
+
+// Plays the moves of the game from its starting position,
+// returning the final position and the plies performed
+func (g *Game) Replay() (*c.Game, []c.Ply, error) {
+	game, err := g.Start()
+	if err != nil {
+		return nil, nil, err
+	}
+	plies := make([]c.Ply, 0, len(g.Moves))
+	for i, m := range g.Moves {
+		p, err := PlyFromMove(game, m)
+		if err != nil {
+			return nil, nil, fmt.Errorf("pdn: move %d (%v): %w", i+1, m, err)
+		}
+		if _, err := game.DoPly(p); err != nil {
+			return nil, nil, err
+		}
+		plies = append(plies, p)
+	}
+	return game, plies, nil
+}
+
+// The squares a ply goes through: its origin, then where it lands after each move
+func plySquares(size byte, p c.Ply) ([]int, error) {
+	var squares []int
+	for _, ins := range p {
+		if ins.Type() != c.MoveInstruction {
+			continue
+		}
+		if len(squares) == 0 {
+			n, ok := c.SquareNumber(size, ins.Row(), ins.Col())
+			if !ok {
+				return nil, fmt.Errorf("ply %v starts outside of a playable square", p)
+			}
+			squares = append(squares, n)
+		}
+		drow, dcol := ins.Destination()
+		n, ok := c.SquareNumber(size, drow, dcol)
+		if !ok {
+			return nil, fmt.Errorf("ply %v goes outside of the playable squares", p)
+		}
+		squares = append(squares, n)
+	}
+	if len(squares) == 0 {
+		return nil, fmt.Errorf("ply %v doesn't move any piece", p)
+	}
+	return squares, nil
+}
+
+func isCapture(p c.Ply) bool {
+	for _, ins := range p {
+		if ins.Type() == c.CaptureInstruction {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the ply matches the move: same origin and destination,
+// and going through the intermediate squares of the move, in order
+func matches(m Move, p c.Ply, squares []int) bool {
+	if m.Capture != isCapture(p) {
+		return false
+	}
+	first, last := m.Squares[0], m.Squares[len(m.Squares)-1]
+	if squares[0] != first || squares[len(squares)-1] != last {
+		return false
+	}
+	between := m.Squares[1 : len(m.Squares)-1]
+	for _, n := range squares[1 : len(squares)-1] {
+		if len(between) > 0 && between[0] == n {
+			between = between[1:]
+		}
+	}
+	return len(between) == 0
+}
+
+// Finds the legal ply of the game that the move refers to
+func PlyFromMove(g *c.Game, m Move) (c.Ply, error) {
+	if len(m.Squares) < 2 {
+		return nil, fmt.Errorf("move %v needs at least two squares", m)
+	}
+	size := g.Board().Size()
+	var found []c.Ply
+	for _, p := range g.Plies() {
+		squares, err := plySquares(size, p)
+		if err != nil {
+			continue
+		}
+		if matches(m, p, squares) {
+			found = append(found, p)
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("illegal move %v", m)
+	}
+	if len(found) > 1 {
+		return nil, fmt.Errorf("ambiguous move %v, could be any of %v", m, found)
+	}
+	return found[0], nil
+}
+
+// Writes the ply as a move in the game, only including the intermediate squares
+// when needed to tell it apart from other legal plies
+func MoveFromPly(g *c.Game, p c.Ply) (Move, error) {
+	squares, err := plySquares(g.Board().Size(), p)
+	if err != nil {
+		return Move{}, err
+	}
+	m := Move{
+		Squares: []int{squares[0], squares[len(squares)-1]},
+		Capture: isCapture(p),
+	}
+	if q, err := PlyFromMove(g, m); err == nil && q.Equals(p) {
+		return m, nil
+	}
+	m.Squares = squares
+	if q, err := PlyFromMove(g, m); err != nil || !q.Equals(p) {
+		return Move{}, fmt.Errorf("ply %v is not legal or can't be told apart from other plies", p)
+	}
+	return m, nil
+}
+
+func resultText(r c.GameResult) string {
+	switch r {
+	case c.WhiteWonResult:
+		return WhiteWinResult
+	case c.BlackWonResult:
+		return BlackWinResult
+	case c.DrawResult:
+		return DrawResultText
+	default:
+		return UnknownResult
+	}
+}
+
+// Makes a PDN game out of the plies performed from the start position.
+// The start game is left as it is.
+func FromPlies(start *c.Game, plies []c.Ply) (*Game, error) {
+	v := start.Variant()
+	g := new(Game)
+
+	if gameType, ok := GameTypeOf(v); ok {
+		g.SetTag("GameType", strconv.Itoa(gameType))
+	}
+
+	initial := c.NewCustomGame(v, nil, FirstPlayer(v))
+	if !start.Board().Equals(initial.Board()) || start.ToPlay() != initial.ToPlay() {
+		g.SetTag("FEN", start.FEN())
+	}
+
+	game := start.Copy()
+	for i, p := range plies {
+		m, err := MoveFromPly(game, p)
+		if err != nil {
+			return nil, fmt.Errorf("pdn: ply %d: %w", i+1, err)
+		}
+		if _, err := game.DoPly(p); err != nil {
+			return nil, err
+		}
+		g.Moves = append(g.Moves, m)
+	}
+
+	g.Result = resultText(game.Result())
+	g.SetTag("Result", g.Result)
+	return g, nil
+}
@@ -0,0 +1,215 @@
+package pdn
+
+import (
+	"math/rand"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func TestReplayAmerican(t *testing.T) {
+	games, err := ReadString(sampleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, plies, err := games[0].Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + game.Board().String())
+	if len(plies) != 8 {
+		t.Errorf("expected 8 plies, got %d", len(plies))
+	}
+	if game.Variant().Name != c.AmericanVariant.Name {
+		t.Errorf("expected an american game, got %v", game.Variant())
+	}
+	// black moved first, so it's black's turn again after 8 plies
+	if game.ToPlay() != c.BlackColor {
+		t.Errorf("expected black to play")
+	}
+	count := game.Board().PieceCount()
+	if count.WhitePawns != 11 || count.BlackPawns != 11 {
+		t.Errorf("expected 11 pawns each after 2 captures, got %+v", count)
+	}
+}
+
+func TestReplayIllegalMove(t *testing.T) {
+	games, err := ReadString(`[GameType "21"] 1. 11-17 *`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := games[0].Replay(); err == nil {
+		t.Errorf("expected error replaying an illegal move")
+	}
+}
+
+func TestGameVariant(t *testing.T) {
+	type test struct {
+		gameType string
+		variant  c.Variant
+	}
+	tests := []test{
+		{"20", c.InternationalVariant},
+		{"21", c.AmericanVariant},
+		{"22", c.ItalianVariant},
+		{"25", c.RussianVariant},
+		{"26,W,8,8,A0,0", c.BrazilianVariant},
+	}
+	for _, test := range tests {
+		g := &Game{Tags: []Tag{{"GameType", test.gameType}}}
+		v, err := g.Variant()
+		if err != nil || v.Name != test.variant.Name {
+			t.Errorf("game type %v: wanted %v got %v (%v)", test.gameType, test.variant, v, err)
+		}
+	}
+
+	v, err := new(Game).Variant()
+	if err != nil || v.Name != c.InternationalVariant.Name {
+		t.Errorf("games without GameType should be international, got %v (%v)", v, err)
+	}
+
+	for _, gameType := range []string{"x", "42"} {
+		g := &Game{Tags: []Tag{{"GameType", gameType}}}
+		if _, err := g.Variant(); err == nil {
+			t.Errorf("expected error for game type %q", gameType)
+		}
+		if _, err := g.Start(); err == nil {
+			t.Errorf("expected error starting game of type %q", gameType)
+		}
+	}
+}
+
+func TestCapturePathDisambiguation(t *testing.T) {
+	b := c.DecodeBoard(`
+		.
+		.
+		.
+		..x.x
+		.
+		..x.x
+		...o
+	`)
+	t.Log("\n" + b.String())
+	// american pawns only capture forward, so both paths end at square 10
+	g := c.NewCustomGame(c.AmericanVariant, b, c.WhiteColor)
+
+	m, _ := ParseMove("26x10")
+	if _, err := PlyFromMove(g, m); err == nil {
+		t.Errorf("expected 26x10 to be ambiguous")
+	}
+
+	left, _ := ParseMove("26x17x10")
+	leftPly, err := PlyFromMove(g, left)
+	if err != nil {
+		t.Fatal(err)
+	}
+	right, _ := ParseMove("26x19x10")
+	rightPly, err := PlyFromMove(g, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leftPly.Equals(rightPly) {
+		t.Errorf("expected different plies for different paths")
+	}
+
+	if got, err := MoveFromPly(g, leftPly); err != nil || got.String() != "26x17x10" {
+		t.Errorf("wanted 26x17x10 got %v (%v)", got, err)
+	}
+
+	if _, err := MoveFromPly(g, c.Ply{c.MakeMoveInstruction(6, 3, 5, 2)}); err == nil {
+		t.Errorf("expected error writing an illegal ply")
+	}
+	if _, err := MoveFromPly(g, c.Ply{c.MakeCrownInstruction(6, 3)}); err == nil {
+		t.Errorf("expected error writing a ply that doesn't move")
+	}
+	if _, err := PlyFromMove(g, Move{Squares: []int{26}}); err == nil {
+		t.Errorf("expected error for a move with a single square")
+	}
+}
+
+func TestFromPliesRoundTrip(t *testing.T) {
+	for _, v := range []c.Variant{c.BrazilianVariant, c.AmericanVariant, c.InternationalVariant, c.RussianVariant, c.ItalianVariant} {
+		start := c.NewCustomGame(v, nil, FirstPlayer(v))
+		g := start.Copy()
+		var plies []c.Ply
+		for !g.Result().Over() {
+			ps := g.Plies()
+			p := ps[rand.Intn(len(ps))]
+			if _, err := g.DoPly(p); err != nil {
+				t.Fatal(err)
+			}
+			plies = append(plies, p)
+		}
+
+		record, err := FromPlies(start, plies)
+		if err != nil {
+			t.Fatalf("%v: %v", v, err)
+		}
+		if _, ok := record.Tag("FEN"); ok {
+			t.Errorf("%v: games from the initial position don't need a FEN tag", v)
+		}
+
+		games, err := ReadString(record.String())
+		if err != nil {
+			t.Fatalf("%v: %v", v, err)
+		}
+		final, replayed, err := games[0].Replay()
+		if err != nil {
+			t.Fatalf("%v: %v\n%v", v, err, record)
+		}
+		if !c.PliesEquals(plies, replayed) {
+			t.Errorf("%v: replayed plies differ", v)
+		}
+		if !final.Equals(g) {
+			t.Errorf("%v: final position differs", v)
+		}
+		if games[0].Result != resultText(g.Result()) {
+			t.Errorf("%v: wanted result %v got %v", v, resultText(g.Result()), games[0].Result)
+		}
+	}
+}
+
+func TestFromPliesCustomPosition(t *testing.T) {
+	b := c.DecodeBoard(`
+		.
+		.
+		.
+		..x.x
+		.
+		..x.x
+		...o
+	`)
+	start := c.NewCustomGame(c.AmericanVariant, b, c.WhiteColor)
+	plies := start.Plies()[:1]
+
+	record, err := FromPlies(start, plies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fen, ok := record.Tag("FEN")
+	if !ok || fen != "W:W26:B14,15,22,23" {
+		t.Errorf("wrong FEN tag %q", fen)
+	}
+	if record.Result != UnknownResult {
+		t.Errorf("game isn't over, got result %v", record.Result)
+	}
+
+	game, _, err := record.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Board().PieceCount().BlackPawns != 2 {
+		t.Errorf("expected 2 black pawns left")
+	}
+
+	if _, err := FromPlies(start, []c.Ply{{c.MakeMoveInstruction(0, 0, 1, 1)}}); err == nil {
+		t.Errorf("expected error for an illegal ply")
+	}
+}
+
+func TestResultText(t *testing.T) {
+	if resultText(c.WhiteWonResult) != "2-0" || resultText(c.BlackWonResult) != "0-2" ||
+		resultText(c.DrawResult) != "1-1" || resultText(c.PlayingResult) != "*" {
+		t.Errorf("wrong result texts")
+	}
+}
@@ -5,8 +5,25 @@ passed = True
 packages_below = []
 
 per_package = {
+    # Go 1.20 doesn't report coverage for packages without tests, newer versions report 0%.
+    # These commands only parse flags and call into the packages above, so they have no tests.
+    'cmd/buildbook': 0.0,
+    'cmd/server': 0.0,
+    'cmd/tablebase': 0.0,
+    'cmd/tournament': 0.0,
+    'cmd/tune': 0.0,
+    'book': 90.0,
+    # main itself only parses flags
+    'cmd/checkers': 80.0,
     'core': 90.0,
+    'internal/random': 90.0,
     'minimax': 90.0,
+    'mcts': 90.0,
+    'pdn': 90.0,
+    'server': 90.0,
+    'tablebase': 90.0,
+    'tournament': 90.0,
+    'tuning': 90.0,
 }
 
 for line in sys.stdin:
@@ -16,7 +33,7 @@ for line in sys.stdin:
     if match_cov is None:
         continue
 
-    match_pkg = re.search(r'github.com/luc527/go_checkers/([a-zA-Z0-9_]+)', line)
+    match_pkg = re.search(r'github.com/luc527/go_checkers/([a-zA-Z0-9_/]+)', line)
     if match_pkg is None:
         print('Failed to match with package name')
         exit(1)
@@ -0,0 +1,589 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+// Rooms are games played live over a WebSocket, at /rooms/{id}/ws:
+//
+//	POST   /rooms         create a room (NewRoomRequest), responds with its RoomState
+//	GET    /rooms/{id}    the RoomState
+//	DELETE /rooms/{id}    close the room, disconnecting everyone
+//	GET    /rooms/{id}/ws join the room
+//
+// Joining with ?color=white or ?color=black takes that seat, responding with a JoinedMessage
+// with the token that resumes it later with ?token=...; joining with neither is watching.
+// Everyone in the room is sent a StateMessage after every change, and the client that
+// sent an invalid message an ErrorMessage. Browser pages of other sites can only join
+// if their origin is in Server.AllowedOrigins.
+
+// Why the game ended, when not by the rules
+const (
+	ResignationTermination = "resignation"
+	TimeForfeitTermination = "time forfeit"
+	AgreementTermination   = "agreement"
+)
+
+// Types of the messages sent by the players
+const (
+	PlyMessage         = "ply"
+	OfferDrawMessage   = "offerDraw"
+	AcceptDrawMessage  = "acceptDraw"
+	DeclineDrawMessage = "declineDraw"
+	ResignMessage      = "resign"
+)
+
+// Types of the messages sent to the clients
+const (
+	JoinedMessage = "joined"
+	StateMessage  = "state"
+	ErrorMessage  = "error"
+)
+
+// Messages a client can fall behind on before it's disconnected
+const clientBuffer = 32
+
+type ClockRequest struct {
+	// In milliseconds, the time each player starts with, and the time added after each of their plies
+	Initial   int `json:"initial"`
+	Increment int `json:"increment"`
+}
+
+type NewRoomRequest struct {
+	NewGameRequest
+	// Optional, no clocks if not given
+	Clock *ClockRequest `json:"clock"`
+}
+
+type ClientMessage struct {
+	Type string `json:"type"`
+	// For a PlyMessage, either one, as in PlyRequest
+	Ply  c.Ply  `json:"ply,omitempty"`
+	Move string `json:"move,omitempty"`
+}
+
+type Clocks struct {
+	// Remaining time in milliseconds
+	White int64 `json:"white"`
+	Black int64 `json:"black"`
+	// Whether the clock of the player to play is running:
+	// from when both players have joined until the game is over
+	Running bool `json:"running"`
+}
+
+type RoomState struct {
+	GameState
+	Termination string   `json:"termination,omitempty"`
+	Clocks      *Clocks  `json:"clocks,omitempty"`
+	DrawOffer   *c.Color `json:"drawOffer,omitempty"`
+	// Whether the players are connected
+	White      bool `json:"white"`
+	Black      bool `json:"black"`
+	Spectators int  `json:"spectators"`
+}
+
+type RoomMessage struct {
+	Type string `json:"type"`
+	// For a JoinedMessage to a player, the seat taken and the token to resume it with
+	Color *c.Color   `json:"color,omitempty"`
+	Token string     `json:"token,omitempty"`
+	State *RoomState `json:"state,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+type client struct {
+	ws   *WebSocket
+	send chan []byte
+	// nil for spectators
+	color *c.Color
+	// no longer in the room, send is closed
+	dropped bool
+}
+
+func (cl *client) write() {
+	for data := range cl.send {
+		if err := cl.ws.WriteMessage(data); err != nil {
+			break
+		}
+	}
+	cl.ws.Close()
+}
+
+type seat struct {
+	// empty until someone takes the seat
+	token  string
+	client *client
+}
+
+// Everything is guarded by the mutex of the game
+type room struct {
+	game
+
+	clocked   bool
+	increment time.Duration
+	remaining [2]time.Duration
+	// zero until the clocks start
+	turnStart time.Time
+	timer     *time.Timer
+
+	// set when the game ended not by the rules
+	termination string
+	over        c.GameResult
+
+	drawOffer  *c.Color
+	seats      [2]seat
+	spectators map[*client]bool
+	closed     bool
+}
+
+func newRoom(req NewRoomRequest) (*room, error) {
+	g, err := newGame(req.NewGameRequest)
+	if err != nil {
+		return nil, err
+	}
+	rm := &room{game: game{g: g}, spectators: make(map[*client]bool)}
+	if cr := req.Clock; cr != nil {
+		if cr.Initial <= 0 || cr.Increment < 0 {
+			return nil, errorf(http.StatusBadRequest, "invalid clock, expected a positive initial time and a non-negative increment")
+		}
+		rm.clocked = true
+		rm.increment = time.Duration(cr.Increment) * time.Millisecond
+		initial := time.Duration(cr.Initial) * time.Millisecond
+		rm.remaining = [2]time.Duration{initial, initial}
+	}
+	return rm, nil
+}
+
+func (rm *room) result() c.GameResult {
+	if rm.termination != "" {
+		return rm.over
+	}
+	return rm.g.Result()
+}
+
+func (rm *room) running() bool {
+	return rm.clocked && !rm.turnStart.IsZero() && !rm.result().Over()
+}
+
+// Remaining time of the player to play
+func (rm *room) left(now time.Time) time.Duration {
+	d := rm.remaining[rm.g.ToPlay()]
+	if rm.running() {
+		d -= now.Sub(rm.turnStart)
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+func (rm *room) state() RoomState {
+	st := RoomState{
+		GameState:   rm.game.state(),
+		Termination: rm.termination,
+		DrawOffer:   rm.drawOffer,
+		White:       rm.seats[c.WhiteColor].client != nil,
+		Black:       rm.seats[c.BlackColor].client != nil,
+		Spectators:  len(rm.spectators),
+	}
+	if rm.termination != "" {
+		st.Result = rm.over
+		st.Plies, st.Moves = []c.Ply{}, []string{}
+	}
+	if rm.clocked {
+		remaining := rm.remaining
+		remaining[rm.g.ToPlay()] = rm.left(time.Now())
+		st.Clocks = &Clocks{
+			White:   remaining[c.WhiteColor].Milliseconds(),
+			Black:   remaining[c.BlackColor].Milliseconds(),
+			Running: rm.running(),
+		}
+	}
+	return st
+}
+
+func (rm *room) send(cl *client, msg RoomMessage) {
+	if cl.dropped {
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	select {
+	case cl.send <- data:
+	default:
+		rm.drop(cl)
+	}
+}
+
+func (rm *room) broadcast() {
+	st := rm.state()
+	msg := RoomMessage{Type: StateMessage, State: &st}
+	for _, s := range rm.seats {
+		if s.client != nil {
+			rm.send(s.client, msg)
+		}
+	}
+	for cl := range rm.spectators {
+		rm.send(cl, msg)
+	}
+}
+
+// Takes the client out of the room, its connection is closed once what was sent to it is written
+func (rm *room) drop(cl *client) {
+	if cl.dropped {
+		return
+	}
+	cl.dropped = true
+	close(cl.send)
+	if cl.color != nil && rm.seats[*cl.color].client == cl {
+		rm.seats[*cl.color].client = nil
+	}
+	delete(rm.spectators, cl)
+}
+
+func (rm *room) join(ws *WebSocket, color, token string) (*client, error) {
+	if rm.closed {
+		return nil, fmt.Errorf("the room is closed")
+	}
+	cl := &client{ws: ws, send: make(chan []byte, clientBuffer)}
+	switch {
+	case token != "":
+		for col := range rm.seats {
+			if rm.seats[col].token != token {
+				continue
+			}
+			if old := rm.seats[col].client; old != nil {
+				rm.drop(old)
+			}
+			taken := c.Color(col)
+			cl.color = &taken
+			rm.seats[col].client = cl
+		}
+		if cl.color == nil {
+			return nil, fmt.Errorf("invalid token")
+		}
+	case color == "" || color == "spectator":
+		rm.spectators[cl] = true
+	default:
+		var col c.Color
+		if err := json.Unmarshal([]byte(fmt.Sprintf("%q", color)), &col); err != nil {
+			return nil, fmt.Errorf("invalid color %q, expected white, black or spectator", color)
+		}
+		if rm.seats[col].token != "" {
+			return nil, fmt.Errorf("%v is taken", col)
+		}
+		cl.color = &col
+		rm.seats[col] = seat{token: newID() + newID(), client: cl}
+		rm.startClock()
+	}
+
+	joined := RoomMessage{Type: JoinedMessage}
+	if cl.color != nil {
+		joined.Color = cl.color
+		joined.Token = rm.seats[*cl.color].token
+	}
+	rm.send(cl, joined)
+	rm.broadcast()
+	return cl, nil
+}
+
+func (rm *room) leave(cl *client) {
+	if !cl.dropped {
+		rm.drop(cl)
+		rm.broadcast()
+	}
+}
+
+// Starts the clocks once both players have joined
+func (rm *room) startClock() {
+	if !rm.clocked || !rm.turnStart.IsZero() || rm.seats[c.WhiteColor].token == "" || rm.seats[c.BlackColor].token == "" {
+		return
+	}
+	rm.turnStart = time.Now()
+	rm.schedule()
+}
+
+// Sets the timer to flag the player to play when their time runs out
+func (rm *room) schedule() {
+	if rm.timer != nil {
+		rm.timer.Stop()
+		rm.timer = nil
+	}
+	if !rm.running() {
+		return
+	}
+	turn := len(rm.history)
+	rm.timer = time.AfterFunc(rm.left(time.Now()), func() {
+		rm.mu.Lock()
+		defer rm.mu.Unlock()
+		if rm.closed || len(rm.history) != turn || !rm.running() {
+			return
+		}
+		if rm.left(time.Now()) > 0 {
+			rm.schedule()
+			return
+		}
+		rm.flag()
+		rm.broadcast()
+	})
+}
+
+// The player to play ran out of time
+func (rm *room) flag() {
+	rm.remaining[rm.g.ToPlay()] = 0
+	rm.end(wonBy(rm.g.ToPlay().Opposite()), TimeForfeitTermination)
+}
+
+func (rm *room) end(result c.GameResult, termination string) {
+	rm.over = result
+	rm.termination = termination
+	rm.drawOffer = nil
+	rm.schedule()
+}
+
+func wonBy(color c.Color) c.GameResult {
+	if color == c.WhiteColor {
+		return c.WhiteWonResult
+	}
+	return c.BlackWonResult
+}
+
+func (rm *room) play(color c.Color, msg ClientMessage) error {
+	if color != rm.g.ToPlay() {
+		return fmt.Errorf("it's not your turn")
+	}
+	p := msg.Ply
+	if msg.Move != "" {
+		m, err := pdn.ParseMove(msg.Move)
+		if err != nil {
+			return err
+		}
+		if p, err = pdn.PlyFromMove(rm.g, m); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	left := rm.left(now)
+	if rm.running() && left <= 0 {
+		// too late, the timer just hasn't gone off yet
+		rm.flag()
+		return nil
+	}
+	running := rm.running()
+	if err := rm.do(p); err != nil {
+		return err
+	}
+	if running {
+		rm.remaining[color] = left + rm.increment
+		rm.turnStart = now
+	}
+	// an offer stands until the other player plays
+	if rm.drawOffer != nil && *rm.drawOffer != color {
+		rm.drawOffer = nil
+	}
+	rm.schedule()
+	return nil
+}
+
+func (rm *room) handle(cl *client, msg ClientMessage) error {
+	if cl.color == nil {
+		return fmt.Errorf("spectators can only watch")
+	}
+	if rm.result().Over() {
+		return fmt.Errorf("the game is over")
+	}
+	color := *cl.color
+	offeredByOther := rm.drawOffer != nil && *rm.drawOffer != color
+	switch msg.Type {
+	case PlyMessage:
+		return rm.play(color, msg)
+	case OfferDrawMessage:
+		if offeredByOther {
+			rm.end(c.DrawResult, AgreementTermination)
+		} else {
+			rm.drawOffer = &color
+		}
+	case AcceptDrawMessage:
+		if !offeredByOther {
+			return fmt.Errorf("no draw was offered to you")
+		}
+		rm.end(c.DrawResult, AgreementTermination)
+	case DeclineDrawMessage:
+		if !offeredByOther {
+			return fmt.Errorf("no draw was offered to you")
+		}
+		rm.drawOffer = nil
+	case ResignMessage:
+		rm.end(wonBy(color.Opposite()), ResignationTermination)
+	default:
+		return fmt.Errorf("unknown message type %q", msg.Type)
+	}
+	return nil
+}
+
+// Disconnects everyone
+func (rm *room) close() {
+	rm.closed = true
+	if rm.timer != nil {
+		rm.timer.Stop()
+	}
+	for _, s := range rm.seats {
+		if s.client != nil {
+			rm.drop(s.client)
+		}
+	}
+	for cl := range rm.spectators {
+		rm.drop(cl)
+	}
+}
+
+func (s *Server) serveRooms(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) == 1 {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		s.createRoom(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	rm, ok := s.rooms[parts[1]]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, errorf(http.StatusNotFound, "no room %q", parts[1]))
+		return
+	}
+	route := ""
+	if len(parts) == 3 {
+		route = parts[2]
+	}
+	switch {
+	case route == "" && r.Method == http.MethodGet:
+		rm.mu.Lock()
+		defer rm.mu.Unlock()
+		writeJSON(w, http.StatusOK, rm.state())
+	case route == "" && r.Method == http.MethodDelete:
+		s.mu.Lock()
+		delete(s.rooms, rm.id)
+		s.mu.Unlock()
+		rm.mu.Lock()
+		rm.close()
+		rm.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case route == "":
+		methodNotAllowed(w, http.MethodGet, http.MethodDelete)
+	case route == "ws":
+		s.connect(w, r, rm)
+	default:
+		writeError(w, errorf(http.StatusNotFound, "not found"))
+	}
+}
+
+func (s *Server) createRoom(w http.ResponseWriter, r *http.Request) {
+	var req NewRoomRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	rm, err := newRoom(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	if s.full() {
+		s.mu.Unlock()
+		writeError(w, errorf(http.StatusServiceUnavailable, "too many games"))
+		return
+	}
+	rm.id = s.newID()
+	// taken before the room can be found by other requests
+	state := rm.state()
+	s.rooms[rm.id] = rm
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, state)
+}
+
+// Requests without an Origin don't come from a browser page, so they're let through
+func (s *Server) originAllowed(origin, host string) bool {
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, host) {
+		return true
+	}
+	for _, o := range s.AllowedOrigins {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) connect(w http.ResponseWriter, r *http.Request, rm *room) {
+	q := r.URL.Query()
+	if origin := r.Header.Get("Origin"); !s.originAllowed(origin, r.Host) {
+		writeError(w, errorf(http.StatusForbidden, "origin %q not allowed", origin))
+		return
+	}
+	ws, err := upgrade(w, r)
+	if err != nil {
+		if !errors.Is(err, errHijacked) {
+			writeError(w, err)
+		}
+		return
+	}
+
+	rm.mu.Lock()
+	cl, err := rm.join(ws, q.Get("color"), q.Get("token"))
+	rm.mu.Unlock()
+	if err != nil {
+		ws.WriteJSON(RoomMessage{Type: ErrorMessage, Error: err.Error()})
+		ws.Close()
+		return
+	}
+	go cl.write()
+
+	for {
+		data, err := ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		var msg ClientMessage
+		err = json.Unmarshal(data, &msg)
+		rm.mu.Lock()
+		if cl.dropped {
+			// replaced by a resumed connection, or too far behind
+			rm.mu.Unlock()
+			break
+		}
+		if err == nil {
+			err = rm.handle(cl, msg)
+		}
+		if err != nil {
+			rm.send(cl, RoomMessage{Type: ErrorMessage, Error: err.Error()})
+		} else {
+			rm.broadcast()
+		}
+		rm.mu.Unlock()
+	}
+
+	rm.mu.Lock()
+	rm.leave(cl)
+	rm.mu.Unlock()
+}
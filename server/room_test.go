@@ -0,0 +1,357 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func createRoom(t *testing.T, s http.Handler, body string) RoomState {
+	t.Helper()
+	var state RoomState
+	if code := do(t, s, http.MethodPost, "/rooms", body, &state); code != http.StatusCreated {
+		t.Fatalf("expected the room to be created, got status %d", code)
+	}
+	return state
+}
+
+func dial(t *testing.T, srv *httptest.Server, path string) *WebSocket {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ws, err := DialWebSocket(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func read(t *testing.T, ws *WebSocket) RoomMessage {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg RoomMessage
+	if err := ws.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// Reads messages until one of the given type satisfying ok
+func readUntil(t *testing.T, ws *WebSocket, typ string, ok func(RoomMessage) bool) RoomMessage {
+	t.Helper()
+	for {
+		msg := read(t, ws)
+		if msg.Type == typ && (ok == nil || ok(msg)) {
+			return msg
+		}
+	}
+}
+
+func readState(t *testing.T, ws *WebSocket, ok func(*RoomState) bool) *RoomState {
+	t.Helper()
+	return readUntil(t, ws, StateMessage, func(msg RoomMessage) bool { return ok(msg.State) }).State
+}
+
+func readError(t *testing.T, ws *WebSocket) string {
+	t.Helper()
+	return readUntil(t, ws, ErrorMessage, nil).Error
+}
+
+func join(t *testing.T, srv *httptest.Server, id, query string) (*WebSocket, RoomMessage) {
+	t.Helper()
+	ws := dial(t, srv, "/rooms/"+id+"/ws"+query)
+	return ws, readUntil(t, ws, JoinedMessage, nil)
+}
+
+func TestRoom(t *testing.T) {
+	s := New()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	id := createRoom(t, s, `{"variant": "american"}`).ID
+
+	white, joined := join(t, srv, id, "?color=white")
+	if joined.Color == nil || *joined.Color != c.WhiteColor || joined.Token == "" {
+		t.Errorf("expected to take the white seat with a token, got %+v", joined)
+	}
+	watcher, joined := join(t, srv, id, "")
+	if joined.Color != nil || joined.Token != "" {
+		t.Errorf("expected to watch, got %+v", joined)
+	}
+	black, _ := join(t, srv, id, "?color=black")
+	st := readState(t, watcher, func(st *RoomState) bool { return st.White && st.Black })
+	if st.Spectators != 1 || st.ToPlay != c.BlackColor || st.Clocks != nil {
+		t.Errorf("expected a spectator, black to play and no clocks, got %+v", st)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for query, want := range map[string]string{
+			"?color=black": "black is taken",
+			"?color=red":   `invalid color "red", expected white, black or spectator`,
+			"?token=wrong": "invalid token",
+		} {
+			ws := dial(t, srv, "/rooms/"+id+"/ws"+query)
+			if msg := read(t, ws); msg.Type != ErrorMessage || msg.Error != want {
+				t.Errorf("%s: expected error %q, got %+v", query, want, msg)
+			}
+		}
+	})
+
+	for _, tc := range []struct {
+		ws   *WebSocket
+		msg  ClientMessage
+		want string
+	}{
+		{watcher, ClientMessage{Type: PlyMessage, Move: "11-15"}, "spectators can only watch"},
+		{white, ClientMessage{Type: PlyMessage, Move: "22-18"}, "it's not your turn"},
+		{black, ClientMessage{Type: PlyMessage, Move: "one"}, ""},
+		{black, ClientMessage{Type: PlyMessage, Move: "11-20"}, ""},
+		{black, ClientMessage{Type: PlyMessage}, ""},
+		{black, ClientMessage{Type: AcceptDrawMessage}, "no draw was offered to you"},
+		{black, ClientMessage{Type: DeclineDrawMessage}, "no draw was offered to you"},
+		{black, ClientMessage{Type: "castle"}, `unknown message type "castle"`},
+	} {
+		if err := tc.ws.WriteJSON(tc.msg); err != nil {
+			t.Fatal(err)
+		}
+		if got := readError(t, tc.ws); got == "" || (tc.want != "" && got != tc.want) {
+			t.Errorf("%+v: expected error %q, got %q", tc.msg, tc.want, got)
+		}
+	}
+	if err := black.WriteMessage([]byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+	readError(t, black)
+
+	black.WriteJSON(ClientMessage{Type: PlyMessage, Move: "11-15"})
+	st = readState(t, watcher, func(st *RoomState) bool { return len(st.History) == 1 })
+	if st.ToPlay != c.WhiteColor || len(st.Plies) == 0 {
+		t.Errorf("expected white to play, got %+v", st)
+	}
+
+	// declined
+	white.WriteJSON(ClientMessage{Type: OfferDrawMessage})
+	readState(t, black, func(st *RoomState) bool { return st.DrawOffer != nil && *st.DrawOffer == c.WhiteColor })
+	black.WriteJSON(ClientMessage{Type: DeclineDrawMessage})
+	readState(t, white, func(st *RoomState) bool { return st.DrawOffer != nil })
+	readState(t, white, func(st *RoomState) bool { return st.DrawOffer == nil })
+
+	// stands after the ply of who offered, not after the other's
+	white.WriteJSON(ClientMessage{Type: OfferDrawMessage})
+	white.WriteJSON(ClientMessage{Type: PlyMessage, Ply: st.Plies[0]})
+	st = readState(t, black, func(st *RoomState) bool { return len(st.History) == 2 })
+	if st.DrawOffer == nil || *st.DrawOffer != c.WhiteColor {
+		t.Errorf("expected white's offer to stand, got %+v", st.DrawOffer)
+	}
+	black.WriteJSON(ClientMessage{Type: PlyMessage, Ply: st.Plies[0]})
+	st = readState(t, white, func(st *RoomState) bool { return len(st.History) == 3 })
+	if st.DrawOffer != nil {
+		t.Errorf("expected the offer to be gone after black's ply")
+	}
+
+	// offering when the other has offered accepts
+	black.WriteJSON(ClientMessage{Type: OfferDrawMessage})
+	readState(t, white, func(st *RoomState) bool { return st.DrawOffer != nil })
+	white.WriteJSON(ClientMessage{Type: OfferDrawMessage})
+	st = readState(t, watcher, func(st *RoomState) bool { return st.Result.Over() })
+	if st.Result != c.DrawResult || st.Termination != AgreementTermination || len(st.Plies) != 0 || st.DrawOffer != nil {
+		t.Errorf("expected a draw by agreement, got %+v", st)
+	}
+	white.WriteJSON(ClientMessage{Type: ResignMessage})
+	if got := readError(t, white); got != "the game is over" {
+		t.Errorf("expected the game to be over, got %q", got)
+	}
+}
+
+func TestRoomAcceptDraw(t *testing.T) {
+	s := New()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	id := createRoom(t, s, `{}`).ID
+	white, _ := join(t, srv, id, "?color=white")
+	black, _ := join(t, srv, id, "?color=black")
+
+	black.WriteJSON(ClientMessage{Type: OfferDrawMessage})
+	readState(t, white, func(st *RoomState) bool { return st.DrawOffer != nil })
+	white.WriteJSON(ClientMessage{Type: AcceptDrawMessage})
+	st := readState(t, black, func(st *RoomState) bool { return st.Result.Over() })
+	if st.Result != c.DrawResult || st.Termination != AgreementTermination {
+		t.Errorf("expected a draw by agreement, got %v, %q", st.Result, st.Termination)
+	}
+}
+
+func TestRoomResume(t *testing.T) {
+	s := New()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	id := createRoom(t, s, `{}`).ID
+
+	white, joined := join(t, srv, id, "?color=white")
+	token := joined.Token
+	black, _ := join(t, srv, id, "?color=black")
+	white.WriteJSON(ClientMessage{Type: PlyMessage, Move: "22-18"})
+	readState(t, black, func(st *RoomState) bool { return len(st.History) == 1 })
+
+	white.Close()
+	readState(t, black, func(st *RoomState) bool { return !st.White })
+
+	white, joined = join(t, srv, id, "?token="+token)
+	if joined.Color == nil || *joined.Color != c.WhiteColor || joined.Token != token {
+		t.Errorf("expected to resume the white seat, got %+v", joined)
+	}
+	st := readState(t, white, func(st *RoomState) bool { return st.White })
+	if len(st.History) != 1 || st.ToPlay != c.BlackColor {
+		t.Errorf("expected the game to go on where it was, got %+v", st)
+	}
+
+	// resuming again takes over from the connection still open
+	again, _ := join(t, srv, id, "?token="+token)
+	white.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, err := white.ReadMessage(); err != nil {
+			break
+		}
+	}
+	again.WriteJSON(ClientMessage{Type: ResignMessage})
+	st = readState(t, black, func(st *RoomState) bool { return st.Result.Over() })
+	if st.Result != c.BlackWonResult || st.Termination != ResignationTermination {
+		t.Errorf("expected white to resign, got %v, %q", st.Result, st.Termination)
+	}
+}
+
+func TestRoomClock(t *testing.T) {
+	s := New()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	id := createRoom(t, s, `{"clock": {"initial": 10000, "increment": 2000}}`).ID
+	white, _ := join(t, srv, id, "?color=white")
+	st := readState(t, white, func(*RoomState) bool { return true })
+	if st.Clocks == nil || st.Clocks.Running || st.Clocks.White != 10000 {
+		t.Errorf("expected the clocks to wait for black, got %+v", st.Clocks)
+	}
+	black, _ := join(t, srv, id, "?color=black")
+	readState(t, black, func(st *RoomState) bool { return st.Clocks.Running })
+	white.WriteJSON(ClientMessage{Type: PlyMessage, Move: "22-18"})
+	st = readState(t, black, func(st *RoomState) bool { return len(st.History) == 1 })
+	if st.Clocks.White <= 10000 || st.Clocks.White > 12000 || st.Clocks.Black > 10000 {
+		t.Errorf("expected white to get the increment, got %+v", st.Clocks)
+	}
+
+	id = createRoom(t, s, `{"clock": {"initial": 50}}`).ID
+	join(t, srv, id, "?color=black")
+	white, _ = join(t, srv, id, "?color=white")
+	st = readState(t, white, func(st *RoomState) bool { return st.Result.Over() })
+	if st.Result != c.BlackWonResult || st.Termination != TimeForfeitTermination || st.Clocks.White != 0 || st.Clocks.Running {
+		t.Errorf("expected white to lose on time, got %v, %q, %+v", st.Result, st.Termination, st.Clocks)
+	}
+}
+
+func TestRoomRoutes(t *testing.T) {
+	s := New()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	id := createRoom(t, s, `{"board": `+captureBoard+`}`).ID
+
+	var st RoomState
+	if code := do(t, s, http.MethodGet, "/rooms/"+id, "", &st); code != http.StatusOK || st.ID != id || len(st.Plies) != 1 {
+		t.Errorf("expected the room's state, got status %d, %+v", code, st)
+	}
+	for _, body := range []string{`{"clock": {"initial": 0}}`, `{"clock": {"initial": 10, "increment": -1}}`, `{"variant": "chess"}`, `[]`} {
+		if code := do(t, s, http.MethodPost, "/rooms", body, nil); code != http.StatusBadRequest {
+			t.Errorf("%s: expected a bad request, got status %d", body, code)
+		}
+	}
+	for _, tc := range []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/rooms", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/rooms/unknown", http.StatusNotFound},
+		{http.MethodPut, "/rooms/" + id, http.StatusMethodNotAllowed},
+		{http.MethodGet, "/rooms/" + id + "/unknown", http.StatusNotFound},
+		{http.MethodGet, "/rooms/" + id + "/ws", http.StatusBadRequest},
+		{http.MethodPost, "/rooms/" + id + "/ws", http.StatusMethodNotAllowed},
+	} {
+		if code := do(t, s, tc.method, tc.path, "", nil); code != tc.code {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.path, tc.code, code)
+		}
+	}
+
+	s.MaxGames = 2
+	create(t, s, `{}`)
+	if code := do(t, s, http.MethodPost, "/rooms", `{}`, nil); code != http.StatusServiceUnavailable {
+		t.Errorf("expected too many games, got status %d", code)
+	}
+
+	white, _ := join(t, srv, id, "?color=white")
+	watcher, _ := join(t, srv, id, "")
+	white.WriteJSON(ClientMessage{Type: PlyMessage, Ply: st.Plies[0]})
+	if st := readState(t, watcher, func(st *RoomState) bool { return st.Result.Over() }); st.Result != c.WhiteWonResult || st.Termination != "" {
+		t.Errorf("expected white to win by the rules, got %v, %q", st.Result, st.Termination)
+	}
+
+	if code := do(t, s, http.MethodDelete, "/rooms/"+id, "", nil); code != http.StatusNoContent {
+		t.Errorf("expected the room to be deleted, got status %d", code)
+	}
+	for _, ws := range []*WebSocket{white, watcher} {
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			if _, err := ws.ReadMessage(); err != nil {
+				break
+			}
+		}
+	}
+	if code := do(t, s, http.MethodGet, "/rooms/"+id, "", nil); code != http.StatusNotFound {
+		t.Errorf("expected the room to be gone, got status %d", code)
+	}
+}
+
+func TestRoomOrigin(t *testing.T) {
+	s := New()
+	s.AllowedOrigins = []string{"https://checkers.example.org"}
+	id := createRoom(t, s, `{}`).ID
+
+	for _, tc := range []struct {
+		origin string
+		code   int
+	}{
+		{"", http.StatusInternalServerError},
+		{"http://example.com", http.StatusInternalServerError},
+		{"https://Checkers.example.org", http.StatusInternalServerError},
+		{"https://evil.example.net", http.StatusForbidden},
+		{"https://checkers.example.org:8443", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	} {
+		// a recorder can't be taken over, so allowed origins fail only there
+		req := httptest.NewRequest(http.MethodGet, "/rooms/"+id+"/ws", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Errorf("%q: expected status %d, got %d", tc.origin, tc.code, rec.Code)
+		}
+	}
+}
+
+func TestRoomClosed(t *testing.T) {
+	rm, err := newRoom(NewRoomRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm.close()
+	if _, err := rm.join(nil, "white", ""); err == nil {
+		t.Errorf("expected joining a closed room to fail")
+	}
+}
@@ -0,0 +1,447 @@
+// Package server serves games of checkers over HTTP, as JSON, keeping them in memory.
+// The boards, plies, colors and results are encoded by their MarshalJSON methods.
+//
+//	POST   /games             create a game (NewGameRequest), responds with its GameState
+//	GET    /games/{id}        the GameState
+//	DELETE /games/{id}        forget the game
+//	GET    /games/{id}/plies  the legal plies (PliesResponse)
+//	POST   /games/{id}/plies  play a ply (PlyRequest), responds with the new GameState
+//	POST   /games/{id}/bot    let a searcher play (BotRequest), responds with a BotResponse
+//	GET    /games/{id}/result the result (ResultResponse)
+//
+// Games can also be played live over a WebSocket, in rooms (see NewRoomRequest).
+// Errors are responded with an ErrorResponse.
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+const (
+	DefaultMaxGames  = 10000
+	DefaultTimeLimit = time.Second
+	maxBodySize      = 1 << 20
+)
+
+type NewGameRequest struct {
+	// Name of the variant, brazilian if empty
+	Variant string `json:"variant"`
+	// Optional, the initial position of the variant if not given
+	Board *c.Board `json:"board"`
+	// Optional, the one that moves first in the variant if not given (see pdn.FirstPlayer)
+	FirstPlayer *c.Color `json:"firstPlayer"`
+	// Optional, overrides the variant's rule, 0 disables it
+	StagnantTurnsToDraw *int16 `json:"stagnantTurnsToDraw"`
+}
+
+// Either the ply, or the move in numbered square notation, e.g. "11-15" or "22x15"
+type PlyRequest struct {
+	Ply  c.Ply  `json:"ply"`
+	Move string `json:"move"`
+}
+
+type BotRequest struct {
+	// Name of the heuristic (see minimax.HeuristicFromString), WeightedCount if empty
+	Heuristic string `json:"heuristic"`
+	// In milliseconds, DefaultTimeLimit if zero, and kept between minimax.MinTimeLimit and minimax.MaxTimeLimit
+	TimeLimit int `json:"timeLimit"`
+}
+
+type GameState struct {
+	ID      string       `json:"id"`
+	Variant string       `json:"variant"`
+	Board   *c.Board     `json:"board"`
+	ToPlay  c.Color      `json:"toPlay"`
+	Result  c.GameResult `json:"result"`
+	FEN     string       `json:"fen"`
+	// Legal plies, and the same plies in numbered square notation
+	Plies []c.Ply  `json:"plies"`
+	Moves []string `json:"moves"`
+	// Plies played so far
+	History []c.Ply `json:"history"`
+}
+
+type PliesResponse struct {
+	Plies []c.Ply  `json:"plies"`
+	Moves []string `json:"moves"`
+}
+
+type BotResponse struct {
+	Ply   c.Ply     `json:"ply"`
+	Move  string    `json:"move"`
+	State GameState `json:"state"`
+}
+
+type ResultResponse struct {
+	Result c.GameResult `json:"result"`
+	// Only when the game has a winner
+	Winner *c.Color `json:"winner,omitempty"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type game struct {
+	// held during the whole request, including the bot's search
+	mu      sync.Mutex
+	id      string
+	g       *c.Game
+	history []c.Ply
+}
+
+// A Server is an http.Handler; make one with New
+type Server struct {
+	// Games and rooms kept at the same time, creating more fails until some are deleted
+	MaxGames int
+	// Origins of other sites whose pages may join rooms, e.g. https://example.com.
+	// Browsers let a page of any site open a WebSocket, so rooms refuse those
+	// that aren't the server's own or listed here.
+	AllowedOrigins []string
+
+	mu    sync.Mutex
+	games map[string]*game
+	rooms map[string]*room
+}
+
+func New() *Server {
+	return &Server{MaxGames: DefaultMaxGames, games: make(map[string]*game), rooms: make(map[string]*room)}
+}
+
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+func errorf(status int, format string, args ...any) error {
+	return &httpError{status, fmt.Sprintf(format, args...)}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if he, ok := err.(*httpError); ok {
+		status = he.status
+	}
+	writeJSON(w, status, ErrorResponse{err.Error()})
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid request: %v", err)
+	}
+	return nil
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, errorf(http.StatusMethodNotAllowed, "method not allowed"))
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if (parts[0] != "games" && parts[0] != "rooms") || len(parts) > 3 {
+		writeError(w, errorf(http.StatusNotFound, "not found"))
+		return
+	}
+	if parts[0] == "rooms" {
+		s.serveRooms(w, r, parts)
+		return
+	}
+	if len(parts) == 1 {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		s.create(w, r)
+		return
+	}
+
+	gm, ok := s.game(parts[1])
+	if !ok {
+		writeError(w, errorf(http.StatusNotFound, "no game %q", parts[1]))
+		return
+	}
+	route := ""
+	if len(parts) == 3 {
+		route = parts[2]
+	}
+	switch {
+	case route == "" && r.Method == http.MethodGet:
+		gm.mu.Lock()
+		defer gm.mu.Unlock()
+		writeJSON(w, http.StatusOK, gm.state())
+	case route == "" && r.Method == http.MethodDelete:
+		s.mu.Lock()
+		delete(s.games, gm.id)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case route == "":
+		methodNotAllowed(w, http.MethodGet, http.MethodDelete)
+	case route == "plies" && r.Method == http.MethodGet:
+		gm.mu.Lock()
+		defer gm.mu.Unlock()
+		plies, moves := legalPlies(gm.g)
+		writeJSON(w, http.StatusOK, PliesResponse{plies, moves})
+	case route == "plies" && r.Method == http.MethodPost:
+		s.play(w, r, gm)
+	case route == "plies":
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	case route == "bot" && r.Method == http.MethodPost:
+		s.bot(w, r, gm)
+	case route == "bot":
+		methodNotAllowed(w, http.MethodPost)
+	case route == "result" && r.Method == http.MethodGet:
+		gm.mu.Lock()
+		defer gm.mu.Unlock()
+		res := ResultResponse{Result: gm.g.Result()}
+		if res.Result.HasWinner() {
+			winner := res.Result.Winner()
+			res.Winner = &winner
+		}
+		writeJSON(w, http.StatusOK, res)
+	case route == "result":
+		methodNotAllowed(w, http.MethodGet)
+	default:
+		writeError(w, errorf(http.StatusNotFound, "not found"))
+	}
+}
+
+func (s *Server) game(id string) (*game, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gm, ok := s.games[id]
+	return gm, ok
+}
+
+func newID() string {
+	var bs [8]byte
+	if _, err := rand.Read(bs[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bs[:])
+}
+
+// Should be called with the lock held
+func (s *Server) newID() string {
+	for {
+		id := newID()
+		_, game := s.games[id]
+		_, room := s.rooms[id]
+		if !game && !room {
+			return id
+		}
+	}
+}
+
+// Should be called with the lock held
+func (s *Server) full() bool {
+	return s.MaxGames > 0 && len(s.games)+len(s.rooms) >= s.MaxGames
+}
+
+func newGame(req NewGameRequest) (*c.Game, error) {
+	name := req.Variant
+	if name == "" {
+		name = c.BrazilianVariant.Name
+	}
+	v, ok := c.VariantFromName(name)
+	if !ok {
+		return nil, errorf(http.StatusBadRequest, "unknown variant %q", name)
+	}
+	if req.StagnantTurnsToDraw != nil {
+		if *req.StagnantTurnsToDraw < 0 {
+			return nil, errorf(http.StatusBadRequest, "negative stagnantTurnsToDraw")
+		}
+		v.StagnantTurnsToDraw = *req.StagnantTurnsToDraw
+	}
+	first := pdn.FirstPlayer(v)
+	if req.FirstPlayer != nil {
+		first = *req.FirstPlayer
+	}
+	size := v.Size
+	if size == 0 {
+		size = c.DefaultBoardSize
+	}
+	if req.Board != nil && req.Board.Size() != size {
+		return nil, errorf(http.StatusBadRequest, "board of size %d for the %s variant, of size %d", req.Board.Size(), v.Name, size)
+	}
+	return c.NewCustomGame(v, req.Board, first), nil
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req NewGameRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	g, err := newGame(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.mu.Lock()
+	if s.full() {
+		s.mu.Unlock()
+		writeError(w, errorf(http.StatusServiceUnavailable, "too many games"))
+		return
+	}
+	gm := &game{id: s.newID(), g: g}
+	// taken before the game can be found by other requests
+	state := gm.state()
+	s.games[gm.id] = gm
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, state)
+}
+
+func legalPlies(g *c.Game) ([]c.Ply, []string) {
+	plies := c.CopyPlies(g.Plies())
+	moves := make([]string, len(plies))
+	for i, p := range plies {
+		moves[i] = moveString(g, p)
+	}
+	return plies, moves
+}
+
+func moveString(g *c.Game, p c.Ply) string {
+	m, err := pdn.MoveFromPly(g, p)
+	if err != nil {
+		return ""
+	}
+	return m.String()
+}
+
+// Should be called with the lock held
+func (gm *game) state() GameState {
+	plies, moves := legalPlies(gm.g)
+	history := gm.history
+	if history == nil {
+		history = []c.Ply{}
+	}
+	return GameState{
+		ID:      gm.id,
+		Variant: gm.g.Variant().Name,
+		// a copy, since the state may be encoded after the lock is released
+		Board:   gm.g.Board().Copy(),
+		ToPlay:  gm.g.ToPlay(),
+		Result:  gm.g.Result(),
+		FEN:     gm.g.FEN(),
+		Plies:   plies,
+		Moves:   moves,
+		History: history,
+	}
+}
+
+// Should be called with the lock held
+func (gm *game) do(p c.Ply) error {
+	if gm.g.Result().Over() {
+		return errorf(http.StatusConflict, "the game is over")
+	}
+	for _, q := range gm.g.Plies() {
+		if q.Equals(p) {
+			gm.g.DoPly(q)
+			gm.history = append(gm.history, q)
+			return nil
+		}
+	}
+	return errorf(http.StatusUnprocessableEntity, "illegal ply %v", p)
+}
+
+func (s *Server) play(w http.ResponseWriter, r *http.Request, gm *game) {
+	var req PlyRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	p := req.Ply
+	if req.Move != "" {
+		if gm.g.Result().Over() {
+			writeError(w, errorf(http.StatusConflict, "the game is over"))
+			return
+		}
+		m, err := pdn.ParseMove(req.Move)
+		if err != nil {
+			writeError(w, errorf(http.StatusBadRequest, "%v", err))
+			return
+		}
+		if p, err = pdn.PlyFromMove(gm.g, m); err != nil {
+			writeError(w, errorf(http.StatusUnprocessableEntity, "%v", err))
+			return
+		}
+	}
+	if err := gm.do(p); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, gm.state())
+}
+
+func (s *Server) bot(w http.ResponseWriter, r *http.Request, gm *game) {
+	var req BotRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	name := req.Heuristic
+	if name == "" {
+		name = "WeightedCount"
+	}
+	h := minimax.HeuristicFromString(name)
+	if h == nil {
+		writeError(w, errorf(http.StatusBadRequest, "unknown heuristic %q", name))
+		return
+	}
+	if req.TimeLimit < 0 {
+		writeError(w, errorf(http.StatusBadRequest, "negative timeLimit"))
+		return
+	}
+	tlim := DefaultTimeLimit
+	if req.TimeLimit > 0 {
+		tlim = time.Duration(req.TimeLimit) * time.Millisecond
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	if gm.g.Result().Over() {
+		writeError(w, errorf(http.StatusConflict, "the game is over"))
+		return
+	}
+	searcher := minimax.TimeLimitedSearcher{ToMax: gm.g.ToPlay(), Heuristic: h, TimeLimit: tlim}
+	p := searcher.SearchContext(r.Context(), gm.g)
+	if p == nil {
+		writeError(w, errorf(http.StatusServiceUnavailable, "the search was cancelled"))
+		return
+	}
+	move := moveString(gm.g, p)
+	if err := gm.do(p); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, BotResponse{Ply: p, Move: move, State: gm.state()})
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// White to play must capture the only black piece, winning
+const captureBoard = `"52wp41bp"`
+
+func do(t *testing.T, s http.Handler, method, path, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: invalid response %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func create(t *testing.T, s http.Handler, body string) GameState {
+	t.Helper()
+	var state GameState
+	if code := do(t, s, http.MethodPost, "/games", body, &state); code != http.StatusCreated {
+		t.Fatalf("expected the game to be created, got status %d", code)
+	}
+	return state
+}
+
+func TestCreate(t *testing.T) {
+	s := New()
+	state := create(t, s, `{}`)
+	if state.ID == "" || state.Variant != c.BrazilianVariant.Name || state.ToPlay != c.WhiteColor || state.Result != c.PlayingResult {
+		t.Errorf("expected a brazilian game with white to play, got %+v", state)
+	}
+	if len(state.Plies) != 7 || len(state.Moves) != 7 || state.Moves[0] == "" || len(state.History) != 0 {
+		t.Errorf("expected the 7 initial plies and no history, got %v, %v, %v", state.Plies, state.Moves, state.History)
+	}
+
+	state = create(t, s, `{"variant": "american", "board": `+captureBoard+`, "firstPlayer": "black", "stagnantTurnsToDraw": 4}`)
+	if state.Variant != c.AmericanVariant.Name || state.ToPlay != c.BlackColor {
+		t.Errorf("expected an american game with black to play, got %+v", state)
+	}
+	var got GameState
+	if code := do(t, s, http.MethodGet, "/games/"+state.ID, "", &got); code != http.StatusOK || got.ID != state.ID || got.FEN != state.FEN {
+		t.Errorf("expected the same state, got status %d, %+v", code, got)
+	}
+	s.mu.Lock()
+	stagnant := s.games[state.ID].g.Variant().StagnantTurnsToDraw
+	s.mu.Unlock()
+	if stagnant != 4 {
+		t.Errorf("expected 4 stagnant turns to draw, got %d", stagnant)
+	}
+
+	for _, body := range []string{
+		``,
+		`{"variant": "chess"}`,
+		`{"board": "52wq"}`,
+		`{"variant": "international", "board": ` + captureBoard + `}`,
+		`{"stagnantTurnsToDraw": -1}`,
+		`{"unknown": 1}`,
+	} {
+		var res ErrorResponse
+		if code := do(t, s, http.MethodPost, "/games", body, &res); code != http.StatusBadRequest || res.Error == "" {
+			t.Errorf("%q: expected a bad request error, got status %d, %+v", body, code, res)
+		}
+	}
+
+	s.MaxGames = 2
+	if code := do(t, s, http.MethodPost, "/games", `{}`, nil); code != http.StatusServiceUnavailable {
+		t.Errorf("expected too many games, got status %d", code)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	s := New()
+	state := create(t, s, `{"board": `+captureBoard+`}`)
+	path := "/games/" + state.ID + "/plies"
+
+	var plies PliesResponse
+	if code := do(t, s, http.MethodGet, path, "", &plies); code != http.StatusOK || len(plies.Plies) != 1 || plies.Moves[0] != state.Moves[0] {
+		t.Fatalf("expected the single capture, got status %d, %+v", code, plies)
+	}
+	if code := do(t, s, http.MethodPost, path, `{"ply": "m0000"}`, nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("expected an illegal ply, got status %d", code)
+	}
+	if code := do(t, s, http.MethodPost, path, `{"move": "1-2"}`, nil); code != http.StatusUnprocessableEntity {
+		t.Errorf("expected an illegal move, got status %d", code)
+	}
+	if code := do(t, s, http.MethodPost, path, `{"move": "one"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected an invalid move, got status %d", code)
+	}
+
+	ply, err := json.Marshal(plies.Plies[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var after GameState
+	if code := do(t, s, http.MethodPost, path, `{"ply": `+string(ply)+`}`, &after); code != http.StatusOK {
+		t.Fatalf("expected the ply to be played, got status %d", code)
+	}
+	if after.Result != c.WhiteWonResult || len(after.Plies) != 0 || len(after.History) != 1 || !after.History[0].Equals(plies.Plies[0]) {
+		t.Errorf("expected white to win with the ply in the history, got %+v", after)
+	}
+	if code := do(t, s, http.MethodPost, path, `{"ply": `+string(ply)+`}`, nil); code != http.StatusConflict {
+		t.Errorf("expected the game to be over, got status %d", code)
+	}
+	if code := do(t, s, http.MethodPost, path, `{"move": "1-2"}`, nil); code != http.StatusConflict {
+		t.Errorf("expected the game to be over, got status %d", code)
+	}
+
+	var res ResultResponse
+	if code := do(t, s, http.MethodGet, "/games/"+state.ID+"/result", "", &res); code != http.StatusOK || res.Result != c.WhiteWonResult || res.Winner == nil || *res.Winner != c.WhiteColor {
+		t.Errorf("expected white to have won, got status %d, %+v", code, res)
+	}
+}
+
+func TestPlayMove(t *testing.T) {
+	s := New()
+	state := create(t, s, `{"variant": "american"}`)
+	var after GameState
+	if code := do(t, s, http.MethodPost, "/games/"+state.ID+"/plies", `{"move": "11-15"}`, &after); code != http.StatusOK {
+		t.Fatalf("expected the move to be played, got status %d", code)
+	}
+	if after.ToPlay != c.WhiteColor || len(after.History) != 1 {
+		t.Errorf("expected white to play after one ply, got %+v", after)
+	}
+
+	var res ResultResponse
+	if code := do(t, s, http.MethodGet, "/games/"+state.ID+"/result", "", &res); code != http.StatusOK || res.Result != c.PlayingResult || res.Winner != nil {
+		t.Errorf("expected the game to go on, got status %d, %+v", code, res)
+	}
+}
+
+func TestStateCopiesBoard(t *testing.T) {
+	gm := &game{g: c.NewGame()}
+	st := gm.state()
+	if err := gm.do(st.Plies[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := st.Board.FEN(st.ToPlay); got != st.FEN {
+		t.Errorf("expected the board of the state not to change with the game, got %s instead of %s", got, st.FEN)
+	}
+}
+
+func TestBot(t *testing.T) {
+	s := New()
+	state := create(t, s, `{"board": `+captureBoard+`}`)
+	path := "/games/" + state.ID + "/bot"
+
+	for _, body := range []string{`{"heuristic": "Unknown"}`, `{"timeLimit": -1}`, `[]`} {
+		if code := do(t, s, http.MethodPost, path, body, nil); code != http.StatusBadRequest {
+			t.Errorf("%q: expected a bad request error, got status %d", body, code)
+		}
+	}
+
+	var res BotResponse
+	if code := do(t, s, http.MethodPost, path, `{"heuristic": "Positional", "timeLimit": 100}`, &res); code != http.StatusOK {
+		t.Fatalf("expected the bot to play, got status %d", code)
+	}
+	if res.Move != state.Moves[0] || !res.Ply.Equals(state.Plies[0]) || res.State.Result != c.WhiteWonResult {
+		t.Errorf("expected the bot to capture and win, got %+v", res)
+	}
+	if code := do(t, s, http.MethodPost, path, `{}`, nil); code != http.StatusConflict {
+		t.Errorf("expected the game to be over, got status %d", code)
+	}
+
+	// the search is cancelled with the request
+	state = create(t, s, `{}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/games/"+state.ID+"/bot", bytes.NewBufferString(`{}`)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the search to be cancelled, got status %d", rec.Code)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	s := New()
+	id := create(t, s, `{}`).ID
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+		allow        string
+	}{
+		{http.MethodGet, "/", http.StatusNotFound, ""},
+		{http.MethodGet, "/games/" + id + "/plies/1", http.StatusNotFound, ""},
+		{http.MethodGet, "/games/" + id + "/unknown", http.StatusNotFound, ""},
+		{http.MethodGet, "/games/unknown", http.StatusNotFound, ""},
+		{http.MethodGet, "/games", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodPut, "/games/" + id, http.StatusMethodNotAllowed, "GET, DELETE"},
+		{http.MethodPut, "/games/" + id + "/plies", http.StatusMethodNotAllowed, "GET, POST"},
+		{http.MethodGet, "/games/" + id + "/bot", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodPost, "/games/" + id + "/result", http.StatusMethodNotAllowed, "GET"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != tc.code || rec.Header().Get("Allow") != tc.allow {
+			t.Errorf("%s %s: expected status %d allowing %q, got %d allowing %q", tc.method, tc.path, tc.code, tc.allow, rec.Code, rec.Header().Get("Allow"))
+		}
+		var res ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res.Error == "" {
+			t.Errorf("%s %s: expected an error response, got %q", tc.method, tc.path, rec.Body.String())
+		}
+	}
+
+	if code := do(t, s, http.MethodDelete, "/games/"+id, "", nil); code != http.StatusNoContent {
+		t.Errorf("expected the game to be deleted, got status %d", code)
+	}
+	if code := do(t, s, http.MethodGet, "/games/"+id, "", nil); code != http.StatusNotFound {
+		t.Errorf("expected the game to be gone, got status %d", code)
+	}
+}
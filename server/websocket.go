@@ -0,0 +1,366 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Opcodes of the frames
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+const (
+	websocketGUID      = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxMessageSize     = maxBodySize
+	closeNormal        = 1000
+	closeProtocolError = 1002
+	closeTooBig        = 1009
+)
+
+var (
+	errProtocol   = errors.New("websocket: protocol error")
+	errMessageBig = errors.New("websocket: message too big")
+)
+
+// A WebSocket is a minimal RFC 6455 connection, enough for the JSON messages of the rooms:
+// no extensions nor subprotocols, and messages are read whole.
+// Reads must be done by a single goroutine, writes can be done by many.
+type WebSocket struct {
+	conn net.Conn
+	br   *bufio.Reader
+	// clients mask the frames they send, servers don't
+	client bool
+
+	wmu       sync.Mutex
+	closeOnce sync.Once
+}
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// Whether the comma-separated header has the token, ignoring case
+func headerHas(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returned by upgrade when the handshake fails after the connection was taken over,
+// so there's no response left to write the error to
+var errHijacked = errors.New("websocket: handshake failed after taking over the connection")
+
+// Takes over the connection of the request, answering the opening handshake
+func upgrade(w http.ResponseWriter, r *http.Request) (*WebSocket, error) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		return nil, errorf(http.StatusMethodNotAllowed, "method not allowed")
+	}
+	if !headerHas(r.Header, "Connection", "upgrade") || !headerHas(r.Header, "Upgrade", "websocket") {
+		return nil, errorf(http.StatusBadRequest, "expected a websocket upgrade")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, errorf(http.StatusUpgradeRequired, "unsupported websocket version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errorf(http.StatusBadRequest, "missing Sec-WebSocket-Key")
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errorf(http.StatusInternalServerError, "the connection can't be taken over")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %v", errHijacked, err)
+	}
+	return &WebSocket{conn: conn, br: rw.Reader}, nil
+}
+
+// Connects to a ws:// (or http://) URL, e.g. of a room: ws://localhost:8080/rooms/{id}/ws?color=white
+func DialWebSocket(ctx context.Context, rawURL string) (*WebSocket, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "ws", "http":
+		u.Scheme = "http"
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "80")
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req := &http.Request{Method: http.MethodGet, URL: u, Host: u.Host, Header: http.Header{}}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer conn.Close()
+		var res ErrorResponse
+		if json.NewDecoder(io.LimitReader(resp.Body, maxBodySize)).Decode(&res) == nil && res.Error != "" {
+			return nil, fmt.Errorf("websocket: handshake failed: %s: %s", resp.Status, res.Error)
+		}
+		return nil, fmt.Errorf("websocket: handshake failed: %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake failed: invalid Sec-WebSocket-Accept")
+	}
+	return &WebSocket{conn: conn, br: br, client: true}, nil
+}
+
+func maskBytes(mask [4]byte, bs []byte) {
+	for i := range bs {
+		bs[i] ^= mask[i%4]
+	}
+}
+
+func (ws *WebSocket) writeFrame(op byte, payload []byte) error {
+	ws.wmu.Lock()
+	defer ws.wmu.Unlock()
+
+	buf := make([]byte, 0, 14+len(payload))
+	buf = append(buf, 0x80|op)
+	var maskBit byte
+	if ws.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xFFFF:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	if ws.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		buf = append(buf, mask[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		maskBytes(mask, buf[start:])
+	} else {
+		buf = append(buf, payload...)
+	}
+	_, err := ws.conn.Write(buf)
+	return err
+}
+
+func (ws *WebSocket) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var h [2]byte
+	if _, err = io.ReadFull(ws.br, h[:]); err != nil {
+		return
+	}
+	fin = h[0]&0x80 != 0
+	op = h[0] & 0x0F
+	masked := h[1]&0x80 != 0
+	// no extensions, so no reserved bits; and only the frames from clients are masked
+	if h[0]&0x70 != 0 || masked == ws.client {
+		err = errProtocol
+		return
+	}
+
+	n := uint64(h[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(ws.br, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(ws.br, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if op >= opClose && (n > 125 || !fin) {
+		err = errProtocol
+		return
+	}
+	if n > maxMessageSize {
+		err = errMessageBig
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(ws.br, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(ws.br, payload); err != nil {
+		return
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return
+}
+
+// Closes with the status code, so the other side knows why
+func (ws *WebSocket) closeWith(code uint16) error {
+	err := net.ErrClosed
+	ws.closeOnce.Do(func() {
+		ws.writeFrame(opClose, binary.BigEndian.AppendUint16(nil, code))
+		err = ws.conn.Close()
+	})
+	return err
+}
+
+func (ws *WebSocket) Close() error {
+	return ws.closeWith(closeNormal)
+}
+
+// Returns the next text or binary message, answering the pings on the way.
+// When the other side closes the connection it returns io.EOF.
+func (ws *WebSocket) ReadMessage() ([]byte, error) {
+	var msg []byte
+	started := false
+	for {
+		fin, op, payload, err := ws.readFrame()
+		switch {
+		case err == errProtocol:
+			ws.closeWith(closeProtocolError)
+			return nil, err
+		case err == errMessageBig:
+			ws.closeWith(closeTooBig)
+			return nil, err
+		case err != nil:
+			return nil, err
+		}
+
+		switch op {
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			ws.closeWith(closeNormal)
+			return nil, io.EOF
+		case opText, opBinary:
+			if started {
+				ws.closeWith(closeProtocolError)
+				return nil, errProtocol
+			}
+			started = true
+			msg = payload
+		case opContinuation:
+			if !started {
+				ws.closeWith(closeProtocolError)
+				return nil, errProtocol
+			}
+			if len(msg)+len(payload) > maxMessageSize {
+				ws.closeWith(closeTooBig)
+				return nil, errMessageBig
+			}
+			msg = append(msg, payload...)
+		default:
+			ws.closeWith(closeProtocolError)
+			return nil, errProtocol
+		}
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// Sends a text message
+func (ws *WebSocket) WriteMessage(data []byte) error {
+	return ws.writeFrame(opText, data)
+}
+
+func (ws *WebSocket) ReadJSON(v any) error {
+	data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (ws *WebSocket) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(data)
+}
+
+// Makes reads fail after the deadline, e.g. so a client doesn't wait forever for a message
+func (ws *WebSocket) SetReadDeadline(t time.Time) error {
+	return ws.conn.SetReadDeadline(t)
+}
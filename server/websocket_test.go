@@ -0,0 +1,224 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A server and a client side connected over TCP
+func pair(t *testing.T) (server, client *WebSocket) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	cc, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server = &WebSocket{conn: sc, br: bufio.NewReader(sc)}
+	client = &WebSocket{conn: cc, br: bufio.NewReader(cc), client: true}
+	t.Cleanup(func() {
+		sc.Close()
+		cc.Close()
+	})
+	for _, ws := range []*WebSocket{server, client} {
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	}
+	return server, client
+}
+
+// A frame as sent by a client, masked
+func clientFrame(fin bool, op byte, payload []byte) []byte {
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	mask := [4]byte{1, 2, 3, 4}
+	frame := append([]byte{b0, 0x80 | byte(len(payload))}, mask[:]...)
+	start := len(frame)
+	frame = append(frame, payload...)
+	maskBytes(mask, frame[start:])
+	return frame
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	server, client := pair(t)
+	for _, n := range []int{0, 10, 200, 70000} {
+		msg := bytes.Repeat([]byte{'a'}, n)
+		go client.WriteMessage(msg)
+		if got, err := server.ReadMessage(); err != nil || !bytes.Equal(got, msg) {
+			t.Errorf("expected the server to read %d bytes, got %d, %v", n, len(got), err)
+		}
+		go server.WriteMessage(msg)
+		if got, err := client.ReadMessage(); err != nil || !bytes.Equal(got, msg) {
+			t.Errorf("expected the client to read %d bytes, got %d, %v", n, len(got), err)
+		}
+	}
+
+	// fragmented, with a ping and a pong in between
+	var frames []byte
+	frames = append(frames, clientFrame(false, opText, []byte("hel"))...)
+	frames = append(frames, clientFrame(true, opPing, []byte("ping"))...)
+	frames = append(frames, clientFrame(true, opPong, nil)...)
+	frames = append(frames, clientFrame(false, opContinuation, []byte("lo "))...)
+	frames = append(frames, clientFrame(true, opContinuation, []byte("world"))...)
+	client.conn.Write(frames)
+	if got, err := server.ReadMessage(); err != nil || string(got) != "hello world" {
+		t.Errorf("expected hello world, got %q, %v", got, err)
+	}
+	if fin, op, payload, err := client.readFrame(); err != nil || !fin || op != opPong || string(payload) != "ping" {
+		t.Errorf("expected the ping to be answered, got %v, %x, %q, %v", fin, op, payload, err)
+	}
+
+	// closed by the client
+	go client.Close()
+	if _, err := server.ReadMessage(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	if _, err := client.ReadMessage(); err == nil {
+		t.Errorf("expected reading after closing to fail")
+	}
+	if err := client.Close(); err == nil {
+		t.Errorf("expected closing twice to fail")
+	}
+}
+
+func TestWebSocketProtocolErrors(t *testing.T) {
+	tooBig := []byte{0x80 | opText, 0x80 | 127}
+	tooBig = binary.BigEndian.AppendUint64(tooBig, maxMessageSize+1)
+	tooBig = append(tooBig, 1, 2, 3, 4)
+	longPing := append([]byte{0x80 | opPing, 0x80 | 126}, 0, 200)
+
+	for name, tc := range map[string]struct {
+		frames []byte
+		want   error
+	}{
+		"unmasked":          {[]byte{0x80 | opText, 0}, errProtocol},
+		"reserved bits":     {append([]byte{0x40}, clientFrame(true, opText, nil)[1:]...), errProtocol},
+		"unknown opcode":    {clientFrame(true, 0x3, nil), errProtocol},
+		"lone continuation": {clientFrame(true, opContinuation, nil), errProtocol},
+		"interrupted":       {append(clientFrame(false, opText, []byte("a")), clientFrame(true, opText, []byte("b"))...), errProtocol},
+		"fragmented ping":   {clientFrame(false, opPing, nil), errProtocol},
+		"long ping":         {longPing, errProtocol},
+		"too big":           {tooBig, errMessageBig},
+		"too big in parts": {append(clientFrame(false, opText, []byte("a")), func() []byte {
+			f := []byte{opContinuation | 0x80, 0x80 | 127}
+			f = binary.BigEndian.AppendUint64(f, maxMessageSize)
+			f = append(f, 0, 0, 0, 0)
+			return append(f, make([]byte, maxMessageSize)...)
+		}()...), errMessageBig},
+		"truncated": {clientFrame(true, opText, []byte("abc"))[:4], io.ErrUnexpectedEOF},
+	} {
+		server, client := pair(t)
+		go func(frames []byte, truncated bool) {
+			client.conn.Write(frames)
+			if truncated {
+				client.conn.(*net.TCPConn).CloseWrite()
+			}
+		}(tc.frames, tc.want == io.ErrUnexpectedEOF)
+		if _, err := server.ReadMessage(); err != tc.want {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
+
+// A response writer taken over by a connection that's already closed
+type closedHijacker struct {
+	*httptest.ResponseRecorder
+}
+
+func (h closedHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, other := net.Pipe()
+	conn.Close()
+	other.Close()
+	return conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)), nil
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+	url := srv.URL + "/rooms/unknown/ws"
+
+	for _, tc := range []struct {
+		header map[string]string
+		code   int
+	}{
+		{map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8"}, http.StatusUpgradeRequired},
+		{map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13"}, http.StatusBadRequest},
+	} {
+		rm := createRoom(t, srv.Config.Handler, `{}`)
+		req := httptest.NewRequest(http.MethodGet, "/rooms/"+rm.ID+"/ws", nil)
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		srv.Config.Handler.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Errorf("%v: expected status %d, got %d", tc.header, tc.code, rec.Code)
+		}
+	}
+
+	// a recorder can't be taken over
+	rm := createRoom(t, srv.Config.Handler, `{}`)
+	req := httptest.NewRequest(http.MethodGet, "/rooms/"+rm.ID+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	rec := httptest.NewRecorder()
+	srv.Config.Handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected an error hijacking a recorder, got status %d", rec.Code)
+	}
+
+	// once taken over, the failed handshake isn't responded
+	hj := closedHijacker{httptest.NewRecorder()}
+	srv.Config.Handler.ServeHTTP(hj, req)
+	if hj.Code != http.StatusOK || hj.Body.Len() != 0 {
+		t.Errorf("expected nothing written after the hijack, got status %d, %q", hj.Code, hj.Body)
+	}
+	if _, err := upgrade(closedHijacker{httptest.NewRecorder()}, req); !errors.Is(err, errHijacked) {
+		t.Errorf("expected errHijacked, got %v", err)
+	}
+
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("expected the accept key of RFC 6455, got %q", got)
+	}
+
+	ctx := context.Background()
+	for _, u := range []string{"wss://localhost", "://", "ws" + strings.TrimPrefix(url, "http"), srv.URL + "/games"} {
+		if _, err := DialWebSocket(ctx, u); err == nil {
+			t.Errorf("%s: expected dialing to fail", u)
+		}
+	}
+	if _, err := DialWebSocket(ctx, "ws"+strings.TrimPrefix(url, "http")); err == nil || !strings.Contains(err.Error(), `no room "unknown"`) {
+		t.Errorf("expected the error of the server, got %v", err)
+	}
+
+	// not a websocket server
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Upgrade", "websocket")
+		w.Header().Set("Connection", "Upgrade")
+		w.WriteHeader(http.StatusSwitchingProtocols)
+	}))
+	defer plain.Close()
+	if _, err := DialWebSocket(ctx, plain.URL); err == nil || !strings.Contains(err.Error(), "Sec-WebSocket-Accept") {
+		t.Errorf("expected an invalid accept key, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package tablebase
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Tablebase files are gzip compressed. After a header with the magic bytes, the
+// format version, the name of the variant and the maximum number of pieces,
+// they have the entries of each class in the order they're solved in: first
+// the positions with white to play, then with black to play, by index, each
+// entry a little endian uint16.
+
+const (
+	fileMagic   = "CKTB"
+	fileVersion = 1
+)
+
+func Write(w io.Writer, t *Tablebase) error {
+	name := t.variant.Name
+	if len(name) > 255 {
+		return fmt.Errorf("tablebase: variant name too long")
+	}
+
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	bw.WriteString(fileMagic)
+	bw.WriteByte(fileVersion)
+	bw.WriteByte(byte(len(name)))
+	bw.WriteString(name)
+	bw.WriteByte(byte(t.pieces))
+
+	var buf [2]byte
+	for _, cl := range t.order {
+		for _, color := range []c.Color{c.WhiteColor, c.BlackColor} {
+			for _, e := range cl.entries[color] {
+				binary.LittleEndian.PutUint16(buf[:], uint16(e))
+				if _, err := bw.Write(buf[:]); err != nil {
+					return fmt.Errorf("tablebase: %w", err)
+				}
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("tablebase: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("tablebase: %w", err)
+	}
+	return nil
+}
+
+// Reads a tablebase written by Write. Its variant must be one of core.Variants.
+func Read(r io.Reader) (*Tablebase, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("tablebase: %w", err)
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+
+	header := make([]byte, len(fileMagic)+2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("tablebase: reading header: %w", err)
+	}
+	if string(header[:len(fileMagic)]) != fileMagic {
+		return nil, fmt.Errorf("tablebase: not a tablebase file")
+	}
+	if version := header[len(fileMagic)]; version != fileVersion {
+		return nil, fmt.Errorf("tablebase: unsupported version %d", version)
+	}
+	name := make([]byte, header[len(fileMagic)+1])
+	if _, err := io.ReadFull(br, name); err != nil {
+		return nil, fmt.Errorf("tablebase: reading header: %w", err)
+	}
+	variant, ok := c.VariantFromName(string(name))
+	if !ok {
+		return nil, fmt.Errorf("tablebase: unknown variant %q", name)
+	}
+	pieces, err := br.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("tablebase: reading header: %w", err)
+	}
+
+	t, err := newTablebase(variant, int(pieces))
+	if err != nil {
+		return nil, err
+	}
+	var buf [2]byte
+	for _, count := range pieceCounts(t.pieces) {
+		cl := t.addClass(count)
+		for _, color := range []c.Color{c.WhiteColor, c.BlackColor} {
+			entries := cl.entries[color]
+			for i := range entries {
+				if _, err := io.ReadFull(br, buf[:]); err != nil {
+					return nil, fmt.Errorf("tablebase: reading entries: %w", err)
+				}
+				entries[i] = entry(binary.LittleEndian.Uint16(buf[:]))
+			}
+		}
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, fmt.Errorf("tablebase: unexpected data after the entries")
+	}
+	return t, nil
+}
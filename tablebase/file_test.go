@@ -0,0 +1,87 @@
+package tablebase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func TestWriteRead(t *testing.T) {
+	tb, err := Generate(c.ItalianVariant, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, tb); err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Variant() != tb.Variant() || read.Pieces() != 2 || read.Positions() != tb.Positions() {
+		t.Fatalf("wrong tablebase read: %v with %d pieces and %d positions", read.Variant(), read.Pieces(), read.Positions())
+	}
+	for count, cl := range tb.classes {
+		for color, entries := range cl.entries {
+			for i, e := range entries {
+				if got := read.classes[count].entries[color][i]; got != e {
+					t.Fatalf("%+v %v %d: wanted %v got %v", count, c.Color(color), i, e.result(), got.result())
+				}
+			}
+		}
+	}
+}
+
+func gzipped(s string) *bytes.Buffer {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+	return &buf
+}
+
+func TestReadErrors(t *testing.T) {
+	var valid bytes.Buffer
+	tb, _ := Generate(c.BrazilianVariant, 2)
+	Write(&valid, tb)
+	zr, _ := gzip.NewReader(&valid)
+	var raw bytes.Buffer
+	raw.ReadFrom(zr)
+
+	tests := map[string]*bytes.Buffer{
+		"not gzip":        bytes.NewBufferString("CKTB"),
+		"empty":           gzipped(""),
+		"wrong magic":     gzipped("ABCD\x01\x09brazilian\x02"),
+		"wrong version":   gzipped("CKTB\x02\x09brazilian\x02"),
+		"short name":      gzipped("CKTB\x01\x09brazil"),
+		"unknown variant": gzipped("CKTB\x01\x06polish\x02"),
+		"no pieces":       gzipped("CKTB\x01\x09brazilian"),
+		"too many pieces": gzipped("CKTB\x01\x09brazilian\x09"),
+		"no entries":      gzipped("CKTB\x01\x09brazilian\x02"),
+		"truncated":       gzipped(raw.String()[:raw.Len()-1]),
+		"trailing data":   gzipped(raw.String() + "\x00"),
+	}
+	for name, r := range tests {
+		if _, err := Read(r); err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if !strings.HasPrefix(err.Error(), "tablebase: ") {
+			t.Errorf("%s: wrong error %q", name, err)
+		}
+	}
+
+	if _, err := Read(gzipped(raw.String())); err != nil {
+		t.Errorf("expected to read the file back, got %v", err)
+	}
+}
+
+func TestWriteLongName(t *testing.T) {
+	tb, _ := Generate(c.BrazilianVariant, 2)
+	tb.variant.Name = strings.Repeat("x", 256)
+	if err := Write(&bytes.Buffer{}, tb); err == nil {
+		t.Errorf("expected an error for a variant name too long")
+	}
+}
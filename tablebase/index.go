@@ -0,0 +1,140 @@
+package tablebase
+
+import (
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Positions of a material class (see class) are numbered by the squares of each
+// group of pieces (white kings, white pawns, black kings, black pawns): the
+// squares of a group are a combination, ranked with the combinatorial number
+// system, and the ranks of the four groups are the digits of the index.
+// Some indices are not valid positions (pieces on the same square, pawns on
+// their crowning row) and are marked as such in the table.
+
+// Squares are numbered from 0 here, unlike in core.SquareNumber
+const maxSquares = 50
+
+// binomial[n][k] is n choose k
+var binomial [maxSquares + 1][MaxPieces + 1]int
+
+func init() {
+	for n := range binomial {
+		binomial[n][0] = 1
+		for k := 1; k <= MaxPieces && k <= n; k++ {
+			binomial[n][k] = binomial[n-1][k-1] + binomial[n-1][k]
+		}
+	}
+}
+
+// Rank of the sorted squares among all combinations of len(squares) squares
+func rankCombination(squares []int) int {
+	r := 0
+	for i, s := range squares {
+		r += binomial[s][i+1]
+	}
+	return r
+}
+
+// Inverse of rankCombination, fills squares (which should have the length of the combination)
+func unrankCombination(r int, squares []int) {
+	for k := len(squares); k > 0; k-- {
+		s := k - 1
+		for binomial[s+1][k] <= r {
+			s++
+		}
+		squares[k-1] = s
+		r -= binomial[s][k]
+	}
+}
+
+// The groups in the order they're indexed
+var groups = [4]struct {
+	c.Color
+	c.Kind
+}{
+	{c.WhiteColor, c.KingKind},
+	{c.WhiteColor, c.PawnKind},
+	{c.BlackColor, c.KingKind},
+	{c.BlackColor, c.PawnKind},
+}
+
+func groupCounts(count c.PieceCount) [4]int {
+	return [4]int{
+		int(count.WhiteKings),
+		int(count.WhitePawns),
+		int(count.BlackKings),
+		int(count.BlackPawns),
+	}
+}
+
+func groupIndex(color c.Color, kind c.Kind) int {
+	i := 0
+	if color == c.BlackColor {
+		i += 2
+	}
+	if kind == c.PawnKind {
+		i++
+	}
+	return i
+}
+
+// Index of the position on the board, whose pieces must match the class
+func (cl *class) index(b *c.Board, squares int) int {
+	var buf [MaxPieces]int
+	var bySquare [4][]int
+	// each group gets its own part of the buffer
+	used := 0
+	for g, k := range cl.counts {
+		bySquare[g] = buf[used : used : used+k]
+		used += k
+	}
+	size := b.Size()
+	for s := 0; s < squares; s++ {
+		row, col, _ := c.SquareCoord(size, s+1)
+		if !b.IsOccupied(row, col) {
+			continue
+		}
+		g := groupIndex(b.Get(row, col))
+		bySquare[g] = append(bySquare[g], s)
+	}
+	index := 0
+	for g := range bySquare {
+		index = index*cl.radix[g] + rankCombination(bySquare[g])
+	}
+	return index
+}
+
+// Places the pieces of the position with the given index on the (empty) board.
+// Returns false if the index isn't a valid position, leaving the board in an unspecified state.
+func (cl *class) place(b *c.Board, index int) bool {
+	var ranks [4]int
+	for g := 3; g >= 0; g-- {
+		ranks[g] = index % cl.radix[g]
+		index /= cl.radix[g]
+	}
+	var buf [MaxPieces]int
+	size := b.Size()
+	for g, group := range groups {
+		squares := buf[:cl.counts[g]]
+		unrankCombination(ranks[g], squares)
+		for _, s := range squares {
+			row, col, _ := c.SquareCoord(size, s+1)
+			if b.IsOccupied(row, col) {
+				return false
+			}
+			if group.Kind == c.PawnKind && row == crowningRow(group.Color, size) {
+				return false
+			}
+			b.Set(row, col, group.Color, group.Kind)
+		}
+	}
+	return true
+}
+
+// Same as the unexported one in core
+func crowningRow(color c.Color, size byte) byte {
+	if color == c.BlackColor {
+		return size - 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package tablebase
+
+import (
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+func TestCombinationRank(t *testing.T) {
+	const n, k = 10, 3
+	seen := make(map[int]bool)
+	squares := make([]int, k)
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			for d := b + 1; d < n; d++ {
+				r := rankCombination([]int{a, b, d})
+				if r < 0 || r >= binomial[n][k] || seen[r] {
+					t.Errorf("%v: invalid or repeated rank %d", []int{a, b, d}, r)
+				}
+				seen[r] = true
+				unrankCombination(r, squares)
+				if squares[0] != a || squares[1] != b || squares[2] != d {
+					t.Errorf("rank %d: wanted %v got %v", r, []int{a, b, d}, squares)
+				}
+			}
+		}
+	}
+	if len(seen) != binomial[n][k] {
+		t.Errorf("expected %d ranks, got %d", binomial[n][k], len(seen))
+	}
+}
+
+func TestClassIndex(t *testing.T) {
+	const size = 6
+	squares := c.SquareCount(size)
+	count := c.PieceCount{WhiteKings: 1, BlackPawns: 2}
+	cl := newClass(count, squares)
+
+	b := c.NewBoard(size)
+	valid := 0
+	for i := range cl.entries[0] {
+		if cl.place(b, i) {
+			valid++
+			if got := b.PieceCount(); got != count {
+				t.Fatalf("index %d: placed %+v", i, got)
+			}
+			if got := cl.index(b, squares); got != i {
+				t.Fatalf("placed index %d, got %d back\n%v", i, got, b)
+			}
+		}
+		clearBoard(b, squares)
+	}
+
+	// pawns can't be on their crowning row, which has 3 of the 18 squares,
+	// and the king can be on any square the pawns aren't
+	want := binomial[15][2] * 16
+	if valid != want {
+		t.Errorf("expected %d valid positions, got %d", want, valid)
+	}
+}
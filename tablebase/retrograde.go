@@ -0,0 +1,192 @@
+package tablebase
+
+import (
+	c "github.com/luc527/go_checkers/core"
+)
+
+// The positions of a class are solved from the end of the game backwards, in
+// order of distance (like a breadth-first search). Plies that capture or crown
+// lead to classes already solved, so each position starts knowing the values
+// of those children, and only waits for the ones in its own class:
+//   - a position is won as soon as one of its children is found lost,
+//     and since they're found in order of distance, that's the shortest win;
+//   - a position is lost when all of its children are found won,
+//     and the last one found is the longest.
+// When a position is solved, its predecessors in the class (the positions
+// that reach it by a simple move) are updated. The positions never solved are draws.
+
+// Has a child that is a draw or lost, so the position can't be lost
+const neverLost = ^uint8(0)
+
+type solved struct {
+	index int32
+	color c.Color
+	entry
+}
+
+// Positions to be solved, by distance. A position may be added more than once,
+// only its first (shortest) time counts.
+type buckets [][]solved
+
+func (bs *buckets) add(s solved) {
+	d := s.distance()
+	for len(*bs) <= d {
+		*bs = append(*bs, nil)
+	}
+	(*bs)[d] = append((*bs)[d], s)
+}
+
+func clearBoard(b *c.Board, squares int) {
+	size := b.Size()
+	for s := 1; s <= squares; s++ {
+		row, col, _ := c.SquareCoord(size, s)
+		if b.IsOccupied(row, col) {
+			b.Clear(row, col)
+		}
+	}
+}
+
+func (t *Tablebase) solve(cl *class) {
+	b := c.NewBoard(t.variant.Size)
+	count := cl.pieceCount()
+	n := len(cl.entries[0])
+
+	var bs buckets
+	// children in the class not found won yet
+	var remaining [2][]uint8
+	// longest win among the children found so far plus one, which the position is lost in
+	// if all children are won, zero if none was found won yet
+	var longest [2][]uint16
+
+	var plies []c.Ply
+	for _, color := range []c.Color{c.WhiteColor, c.BlackColor} {
+		entries := cl.entries[color]
+		remaining[color] = make([]uint8, n)
+		longest[color] = make([]uint16, n)
+		for i := range entries {
+			if !cl.place(b, i) {
+				entries[i] = invalidEntry
+				clearBoard(b, t.squares)
+				continue
+			}
+			plies = c.GeneratePlies(plies[:0], b, color, t.variant)
+			if len(plies) == 0 {
+				bs.add(solved{int32(i), color, lossEntry})
+			}
+			inClass := 0
+			for _, p := range plies {
+				c.PerformInstructions(b, p)
+				if b.PieceCount() == count {
+					inClass++
+				} else {
+					t.childSolved(&bs, int32(i), color, t.lookup(b, color.Opposite()), remaining, longest)
+				}
+				c.UndoInstructions(b, p)
+			}
+			clearBoard(b, t.squares)
+			if remaining[color][i] != neverLost {
+				remaining[color][i] = uint8(inClass)
+				if inClass == 0 && len(plies) > 0 && longest[color][i] > 0 {
+					// all children are won, and in other classes
+					bs.add(solved{int32(i), color, lossEntry | entry(longest[color][i])})
+				}
+			}
+		}
+	}
+
+	var preds []int32
+	for d := 0; d < len(bs); d++ {
+		for _, s := range bs[d] {
+			entries := cl.entries[s.color]
+			if entries[s.index] != drawEntry {
+				continue
+			}
+			entries[s.index] = s.entry
+			cl.place(b, int(s.index))
+			preds = t.predecessors(preds[:0], cl, b, s.color.Opposite())
+			clearBoard(b, t.squares)
+			for _, i := range preds {
+				if cl.entries[s.color.Opposite()][i] == drawEntry {
+					t.childSolved(&bs, i, s.color.Opposite(), s.entry, remaining, longest)
+				}
+			}
+		}
+		bs[d] = nil
+	}
+}
+
+// Updates the position (index, color) with the entry of one of its children.
+// Children in the class must only be given after the initial pass, in order of distance.
+func (t *Tablebase) childSolved(bs *buckets, index int32, color c.Color, child entry, remaining [2][]uint8, longest [2][]uint16) {
+	d := child.distance()
+	switch child.outcome() {
+	case lossEntry:
+		bs.add(solved{index, color, winEntry | entry(d+1)})
+		remaining[color][index] = neverLost
+	case winEntry:
+		if uint16(d+1) > longest[color][index] {
+			longest[color][index] = uint16(d + 1)
+		}
+		if r := remaining[color][index]; r != neverLost && r > 0 {
+			remaining[color][index]--
+			if r == 1 {
+				bs.add(solved{index, color, lossEntry | entry(longest[color][index])})
+			}
+		}
+	default:
+		remaining[color][index] = neverLost
+	}
+}
+
+// Indices of the positions of the class, with mover to play, from which mover can make a simple move
+// (not a capture, not crowning) and reach the position on the board. The board is left as it was.
+func (t *Tablebase) predecessors(preds []int32, cl *class, b *c.Board, mover c.Color) []int32 {
+	size := b.Size()
+	var plies []c.Ply
+	for s := 1; s <= t.squares; s++ {
+		row, col, _ := c.SquareCoord(size, s)
+		if !b.IsOccupied(row, col) {
+			continue
+		}
+		color, kind := b.Get(row, col)
+		if color != mover {
+			continue
+		}
+		for _, roff := range []int{-1, +1} {
+			if kind == c.PawnKind && roff != backward(mover) {
+				continue
+			}
+			for _, coff := range []int{-1, +1} {
+				for dist := 1; ; dist++ {
+					srow, scol := byte(int(row)+dist*roff), byte(int(col)+dist*coff)
+					if srow >= size || scol >= size || b.IsOccupied(srow, scol) {
+						break
+					}
+					b.Move(row, col, srow, scol)
+					// the move must have been legal, e.g. not when a capture was mandatory
+					plies = c.GeneratePlies(plies[:0], b, mover, t.variant)
+					move := c.Ply{c.MakeMoveInstruction(srow, scol, row, col)}
+					for _, p := range plies {
+						if p.Equals(move) {
+							preds = append(preds, int32(cl.index(b, t.squares)))
+							break
+						}
+					}
+					b.Move(srow, scol, row, col)
+					if kind == c.PawnKind || !t.variant.FlyingKings {
+						break
+					}
+				}
+			}
+		}
+	}
+	return preds
+}
+
+// Direction of the rows a pawn of the color came from
+func backward(color c.Color) int {
+	if color == c.BlackColor {
+		return -1
+	}
+	return +1
+}
@@ -0,0 +1,239 @@
+// Package tablebase solves endgames with few pieces by retrograde analysis,
+// so searches can know their exact result instead of estimating it.
+package tablebase
+
+import (
+	"fmt"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Maximum number of pieces on the board a tablebase can be generated for
+const MaxPieces = 5
+
+// Outcome of a position for the player to move
+type Outcome byte
+
+const (
+	Draw = Outcome(iota)
+	Win
+	Loss
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Draw:
+		return "draw"
+	case Win:
+		return "win"
+	case Loss:
+		return "loss"
+	default:
+		return "INVALID Outcome"
+	}
+}
+
+// Result of a position with perfect play from both players
+type Result struct {
+	Outcome
+	// Plies until the game ends: the winner ends it as soon as possible,
+	// and the loser as late as possible. Zero for draws.
+	Distance int
+}
+
+// An entry has the outcome in the 2 high bits and the distance in the others
+type entry uint16
+
+const (
+	// also positions not solved yet, while generating
+	drawEntry    = entry(0)
+	winEntry     = entry(1 << 14)
+	lossEntry    = entry(2 << 14)
+	invalidEntry = entry(3 << 14)
+	distanceMask = entry(1<<14 - 1)
+)
+
+func (e entry) outcome() entry {
+	return e &^ distanceMask
+}
+
+func (e entry) distance() int {
+	return int(e & distanceMask)
+}
+
+func (e entry) result() Result {
+	switch e.outcome() {
+	case winEntry:
+		return Result{Win, e.distance()}
+	case lossEntry:
+		return Result{Loss, e.distance()}
+	default:
+		return Result{}
+	}
+}
+
+// A class has all positions with a certain number of each kind of piece
+type class struct {
+	// number of pieces in each group (see groups)
+	counts [4]int
+	// number of combinations of squares of each group
+	radix [4]int
+	// indexed by the color to play, then by the index of the position
+	entries [2][]entry
+}
+
+func newClass(count c.PieceCount, squares int) *class {
+	cl := &class{counts: groupCounts(count)}
+	n := 1
+	for g, k := range cl.counts {
+		cl.radix[g] = binomial[squares][k]
+		n *= cl.radix[g]
+	}
+	cl.entries[c.WhiteColor] = make([]entry, n)
+	cl.entries[c.BlackColor] = make([]entry, n)
+	return cl
+}
+
+func (cl *class) pieceCount() c.PieceCount {
+	return c.PieceCount{
+		WhiteKings: int8(cl.counts[0]),
+		WhitePawns: int8(cl.counts[1]),
+		BlackKings: int8(cl.counts[2]),
+		BlackPawns: int8(cl.counts[3]),
+	}
+}
+
+// A Tablebase has the results of all positions with up to a certain number of pieces
+// in a variant. It only knows how games end by the players running out of pieces or
+// plies, so the draw rules of the variant (e.g. StagnantTurnsToDraw) are not taken into account.
+type Tablebase struct {
+	variant c.Variant
+	pieces  int
+	squares int
+	classes map[c.PieceCount]*class
+	// in the order they were solved, each one only depends on the ones before and on itself
+	order []*class
+}
+
+// Piece counts of the classes of positions with up to maxPieces pieces, with at least one of each color.
+// Captures only lead to classes with less pieces, and crowning pawns to classes with less pawns,
+// so they're sorted by the number of pieces, then by the number of pawns.
+func pieceCounts(maxPieces int) []c.PieceCount {
+	var counts []c.PieceCount
+	for total := 2; total <= maxPieces; total++ {
+		for pawns := 0; pawns <= total; pawns++ {
+			for whites := 1; whites < total; whites++ {
+				blacks := total - whites
+				for whitePawns := 0; whitePawns <= whites && whitePawns <= pawns; whitePawns++ {
+					blackPawns := pawns - whitePawns
+					if blackPawns > blacks {
+						continue
+					}
+					counts = append(counts, c.PieceCount{
+						WhiteKings: int8(whites - whitePawns),
+						WhitePawns: int8(whitePawns),
+						BlackKings: int8(blacks - blackPawns),
+						BlackPawns: int8(blackPawns),
+					})
+				}
+			}
+		}
+	}
+	return counts
+}
+
+func newTablebase(variant c.Variant, maxPieces int) (*Tablebase, error) {
+	if maxPieces < 2 || maxPieces > MaxPieces {
+		return nil, fmt.Errorf("tablebase: number of pieces must be between 2 and %d, got %d", MaxPieces, maxPieces)
+	}
+	if variant.Size == 0 {
+		variant.Size = c.DefaultBoardSize
+	}
+	if variant.Size < 4 || variant.Size > c.MaxBoardSize || variant.Size%2 != 0 {
+		return nil, fmt.Errorf("tablebase: invalid board size %d", variant.Size)
+	}
+	return &Tablebase{
+		variant: variant,
+		pieces:  maxPieces,
+		squares: c.SquareCount(variant.Size),
+		classes: make(map[c.PieceCount]*class),
+	}, nil
+}
+
+func (t *Tablebase) addClass(count c.PieceCount) *class {
+	cl := newClass(count, t.squares)
+	t.classes[count] = cl
+	t.order = append(t.order, cl)
+	return cl
+}
+
+// Solves all positions of the variant with up to maxPieces pieces on the board.
+// On a 8x8 board, it takes a few seconds for 3 pieces, and much longer for each piece more.
+func Generate(variant c.Variant, maxPieces int) (*Tablebase, error) {
+	t, err := newTablebase(variant, maxPieces)
+	if err != nil {
+		return nil, err
+	}
+	for _, count := range pieceCounts(maxPieces) {
+		t.solve(t.addClass(count))
+	}
+	return t, nil
+}
+
+func (t *Tablebase) Variant() c.Variant {
+	return t.variant
+}
+
+// Maximum number of pieces of the positions in the tablebase
+func (t *Tablebase) Pieces() int {
+	return t.pieces
+}
+
+// Number of entries, including the ones for invalid positions
+func (t *Tablebase) Positions() int {
+	n := 0
+	for _, cl := range t.order {
+		n += 2 * len(cl.entries[0])
+	}
+	return n
+}
+
+// Entry of the position on the board, which must have at most as many pieces as the tablebase
+func (t *Tablebase) lookup(b *c.Board, toPlay c.Color) entry {
+	count := b.PieceCount()
+	if toPlay == c.WhiteColor && count.WhiteKings+count.WhitePawns == 0 ||
+		toPlay == c.BlackColor && count.BlackKings+count.BlackPawns == 0 {
+		return lossEntry
+	}
+	cl := t.classes[count]
+	return cl.entries[toPlay][cl.index(b, t.squares)]
+}
+
+// The draw rules don't change the entries of the tablebase
+func sameRules(v, w c.Variant) bool {
+	for _, x := range []*c.Variant{&v, &w} {
+		x.Name = ""
+		if x.Size == 0 {
+			x.Size = c.DefaultBoardSize
+		}
+		x.StagnantTurnsToDraw = 0
+		x.SpecialEndingTurnsToDraw = 0
+		x.RepetitionsToDraw = 0
+	}
+	return v == w
+}
+
+// Result of the game for the player to move, if it's in the tablebase:
+// the game must be of the same variant (except for the draw rules), and
+// have at most as many pieces as the tablebase, at least one of each color.
+func (t *Tablebase) Probe(g *c.Game) (Result, bool) {
+	if !sameRules(g.Variant(), t.variant) {
+		return Result{}, false
+	}
+	b := g.Board()
+	cl, ok := t.classes[b.PieceCount()]
+	if !ok {
+		return Result{}, false
+	}
+	return cl.entries[g.ToPlay()][cl.index(b, t.squares)].result(), true
+}
@@ -0,0 +1,193 @@
+package tablebase
+
+import (
+	"sync"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+)
+
+// Small enough to generate the tablebase with 3 pieces quickly
+var smallVariant = func() c.Variant {
+	v := c.AmericanVariant
+	v.Name = "american 6x6"
+	v.Size = 6
+	return v
+}()
+
+var (
+	smallOnce sync.Once
+	small     *Tablebase
+	smallErr  error
+)
+
+func smallTablebase(t *testing.T) *Tablebase {
+	t.Helper()
+	smallOnce.Do(func() {
+		small, smallErr = Generate(smallVariant, 3)
+	})
+	if smallErr != nil {
+		t.Fatal(smallErr)
+	}
+	return small
+}
+
+func TestGenerateErrors(t *testing.T) {
+	if _, err := Generate(c.BrazilianVariant, 1); err == nil {
+		t.Errorf("expected an error for 1 piece")
+	}
+	if _, err := Generate(c.BrazilianVariant, MaxPieces+1); err == nil {
+		t.Errorf("expected an error for more than %d pieces", MaxPieces)
+	}
+	v := c.BrazilianVariant
+	v.Size = 7
+	if _, err := Generate(v, 2); err == nil {
+		t.Errorf("expected an error for an odd board size")
+	}
+}
+
+func TestPieceCounts(t *testing.T) {
+	counts := pieceCounts(3)
+	if len(counts) != 16 {
+		t.Errorf("expected 16 classes with up to 3 pieces, got %d", len(counts))
+	}
+	prevTotal, prevPawns := 0, 0
+	for _, count := range counts {
+		whites := count.WhiteKings + count.WhitePawns
+		blacks := count.BlackKings + count.BlackPawns
+		total, pawns := int(whites+blacks), int(count.WhitePawns+count.BlackPawns)
+		if whites == 0 || blacks == 0 {
+			t.Errorf("%+v: the game is already over", count)
+		}
+		if total < prevTotal || total == prevTotal && pawns < prevPawns {
+			t.Errorf("%+v: out of order", count)
+		}
+		prevTotal, prevPawns = total, pawns
+	}
+}
+
+// Every result must agree with the results of the positions after each ply
+func TestResultsConsistent(t *testing.T) {
+	tb := smallTablebase(t)
+	b := c.NewBoard(smallVariant.Size)
+	for _, cl := range tb.order {
+		for _, color := range []c.Color{c.WhiteColor, c.BlackColor} {
+			for i := range cl.entries[color] {
+				if !cl.place(b, i) {
+					clearBoard(b, tb.squares)
+					continue
+				}
+				g := c.NewCustomGame(smallVariant, b.Copy(), color)
+				clearBoard(b, tb.squares)
+				r, ok := tb.Probe(g)
+				if !ok {
+					t.Fatalf("position not found\n%v", g.Board())
+				}
+				assertConsistent(t, tb, g, r)
+			}
+		}
+	}
+}
+
+func assertConsistent(t *testing.T, tb *Tablebase, g *c.Game, r Result) {
+	t.Helper()
+	minLoss, maxWin := -1, -1
+	allWins := true
+	for _, p := range g.Plies() {
+		undo, _ := g.DoPly(p)
+		child, ok := tb.Probe(g)
+		if !ok {
+			// no pieces left to play with
+			child = Result{Loss, 0}
+		}
+		g.UndoPly(undo)
+		switch child.Outcome {
+		case Loss:
+			if minLoss < 0 || child.Distance < minLoss {
+				minLoss = child.Distance
+			}
+			allWins = false
+		case Win:
+			if child.Distance > maxWin {
+				maxWin = child.Distance
+			}
+		default:
+			allWins = false
+		}
+	}
+	var want Result
+	if minLoss >= 0 {
+		want = Result{Win, minLoss + 1}
+	} else if allWins {
+		want = Result{Loss, maxWin + 1}
+	}
+	if r != want {
+		t.Fatalf("%v to play: wanted %+v got %+v\n%v", g.ToPlay(), want, r, g.Board())
+	}
+}
+
+func TestProbe(t *testing.T) {
+	tb, err := Generate(c.BrazilianVariant, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		board  string
+		toPlay c.Color
+		want   Result
+	}{
+		// either player captures the other's only piece
+		{".\n.\n.\n.\n...x\n..o", c.WhiteColor, Result{Win, 1}},
+		{".\n.\n.\n.\n...x\n..o", c.BlackColor, Result{Win, 1}},
+		// the pawn can't move
+		{".#\no", c.WhiteColor, Result{Loss, 0}},
+		// kings can't catch each other
+		{"...#\n.\n.\n.\n.\n.\n.\n..@", c.WhiteColor, Result{Draw, 0}},
+	}
+	for _, test := range tests {
+		g := c.NewCustomGame(c.BrazilianVariant, c.DecodeBoard(test.board), test.toPlay)
+		r, ok := tb.Probe(g)
+		if !ok {
+			t.Errorf("position not found\n%v", g.Board())
+			continue
+		}
+		if r != test.want {
+			t.Errorf("%v to play: wanted %+v got %+v\n%v", test.toPlay, test.want, r, g.Board())
+		}
+	}
+}
+
+func TestProbeNotFound(t *testing.T) {
+	tb := smallTablebase(t)
+	b := c.DecodeSizedBoard(6, ".x\n.\n.\n.\n.\no")
+
+	if _, ok := tb.Probe(c.NewCustomGame(smallVariant, b, c.WhiteColor)); !ok {
+		t.Errorf("expected to find the position")
+	}
+
+	v := smallVariant
+	v.StagnantTurnsToDraw = 0
+	v.RepetitionsToDraw = 0
+	if _, ok := tb.Probe(c.NewCustomGame(v, b, c.WhiteColor)); !ok {
+		t.Errorf("the draw rules shouldn't matter")
+	}
+
+	v = smallVariant
+	v.FlyingKings = true
+	if _, ok := tb.Probe(c.NewCustomGame(v, b, c.WhiteColor)); ok {
+		t.Errorf("expected not to find a position of another variant")
+	}
+
+	if _, ok := tb.Probe(c.NewCustomGame(smallVariant, nil, c.WhiteColor)); ok {
+		t.Errorf("expected not to find a position with too many pieces")
+	}
+}
+
+func TestOutcomeString(t *testing.T) {
+	for o, s := range map[Outcome]string{Draw: "draw", Win: "win", Loss: "loss", Outcome(7): "INVALID Outcome"} {
+		if o.String() != s {
+			t.Errorf("wanted %q got %q", s, o.String())
+		}
+	}
+}
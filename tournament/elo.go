@@ -0,0 +1,133 @@
+package tournament
+
+import (
+	"fmt"
+	"math"
+)
+
+// Wins, draws and losses of a player against another
+type Score struct {
+	Wins, Draws, Losses int
+}
+
+func (s Score) Games() int {
+	return s.Wins + s.Draws + s.Losses
+}
+
+// Points per game, a win being 1 point and a draw half a point
+func (s Score) Ratio() float64 {
+	if s.Games() == 0 {
+		return 0
+	}
+	return (float64(s.Wins) + float64(s.Draws)/2) / float64(s.Games())
+}
+
+// The same score from the opponent's point of view
+func (s Score) Reverse() Score {
+	return Score{Wins: s.Losses, Draws: s.Draws, Losses: s.Wins}
+}
+
+func (s Score) String() string {
+	return fmt.Sprintf("+%d =%d -%d", s.Wins, s.Draws, s.Losses)
+}
+
+// Variance of the points of a single game
+func (s Score) variance() float64 {
+	n := float64(s.Games())
+	mean := s.Ratio()
+	w, d, l := float64(s.Wins)/n, float64(s.Draws)/n, float64(s.Losses)/n
+	return w*(1-mean)*(1-mean) + d*(0.5-mean)*(0.5-mean) + l*mean*mean
+}
+
+// Elo rating difference that gives the expected score
+func eloFromRatio(ratio float64) float64 {
+	if ratio == 0.5 {
+		// not -0
+		return 0
+	}
+	return -400 * math.Log10(1/ratio-1)
+}
+
+// Expected score given the Elo rating difference
+func ratioFromElo(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// Estimated Elo rating difference between the player and the opponent, and the margin of
+// its 95% confidence interval. Infinite when the player won or lost every game; zero without games.
+func (s Score) Elo() (diff, margin float64) {
+	if s.Games() == 0 {
+		return 0, 0
+	}
+	ratio := s.Ratio()
+	diff = eloFromRatio(ratio)
+	stderr := math.Sqrt(s.variance() / float64(s.Games()))
+	lo, hi := ratio-1.96*stderr, ratio+1.96*stderr
+	if lo <= 0 || hi >= 1 {
+		return diff, math.Inf(1)
+	}
+	return diff, (eloFromRatio(hi) - eloFromRatio(lo)) / 2
+}
+
+type SPRTStatus int
+
+const (
+	SPRTContinue SPRTStatus = iota
+	// The difference is Elo0 rather than Elo1, e.g. a change didn't make the engine stronger
+	SPRTAcceptH0
+	// The difference is Elo1 rather than Elo0
+	SPRTAcceptH1
+)
+
+func (s SPRTStatus) String() string {
+	switch s {
+	case SPRTAcceptH0:
+		return "H0 accepted"
+	case SPRTAcceptH1:
+		return "H1 accepted"
+	default:
+		return "continue"
+	}
+}
+
+// A sequential probability ratio test of whether a player is stronger than its opponent:
+// after each game it tells whether there's enough evidence to stop playing.
+// The hypotheses are that the Elo rating difference is Elo0 (H0) or Elo1 (H1),
+// with chances Alpha of accepting H1 when H0 is true and Beta of the opposite.
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+}
+
+// Stopping bounds of the log-likelihood ratio
+func (t SPRT) Bounds() (lower, upper float64) {
+	return math.Log(t.Beta / (1 - t.Alpha)), math.Log((1 - t.Beta) / t.Alpha)
+}
+
+// Log-likelihood ratio of H1 over H0 given the score, approximating
+// the points per game by a normal distribution
+func (t SPRT) LLR(s Score) float64 {
+	if s.Games() == 0 {
+		return 0
+	}
+	variance := s.variance()
+	if variance == 0 {
+		// all games had the same result, count one more win and loss so it isn't zero
+		variance = Score{s.Wins + 1, s.Draws, s.Losses + 1}.variance()
+	}
+	s0, s1 := ratioFromElo(t.Elo0), ratioFromElo(t.Elo1)
+	return float64(s.Games()) * (s1 - s0) * (2*s.Ratio() - s0 - s1) / (2 * variance)
+}
+
+func (t SPRT) Status(s Score) SPRTStatus {
+	llr := t.LLR(s)
+	lower, upper := t.Bounds()
+	switch {
+	case llr <= lower:
+		return SPRTAcceptH0
+	case llr >= upper:
+		return SPRTAcceptH1
+	default:
+		return SPRTContinue
+	}
+}
@@ -0,0 +1,108 @@
+package tournament
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	s := Score{Wins: 3, Draws: 2, Losses: 1}
+	if s.Games() != 6 {
+		t.Errorf("expected 6 games, got %d", s.Games())
+	}
+	if ratio := s.Ratio(); math.Abs(ratio-4.0/6) > 1e-9 {
+		t.Errorf("expected a ratio of %g, got %g", 4.0/6, ratio)
+	}
+	if r := s.Reverse(); r != (Score{Wins: 1, Draws: 2, Losses: 3}) {
+		t.Errorf("wrong reverse score %v", r)
+	}
+	if str := s.String(); str != "+3 =2 -1" {
+		t.Errorf("wrong string %q", str)
+	}
+	if ratio := (Score{}).Ratio(); ratio != 0 {
+		t.Errorf("expected a ratio of 0 without games, got %g", ratio)
+	}
+}
+
+func TestElo(t *testing.T) {
+	if diff, margin := (Score{}).Elo(); diff != 0 || margin != 0 {
+		t.Errorf("expected no difference without games, got %g ± %g", diff, margin)
+	}
+
+	even := Score{Wins: 40, Draws: 20, Losses: 40}
+	diff, margin := even.Elo()
+	if math.Abs(diff) > 1e-9 || margin <= 0 || math.IsInf(margin, 0) {
+		t.Errorf("expected an even score to give 0 ± some margin, got %g ± %g", diff, margin)
+	}
+
+	diff, _ = Score{Wins: 75, Losses: 25}.Elo()
+	if math.Abs(diff-190.85) > 0.01 {
+		t.Errorf("expected a 75%% score to be about +190.85, got %g", diff)
+	}
+	if reversed, _ := (Score{Wins: 25, Losses: 75}).Elo(); math.Abs(reversed+diff) > 1e-9 {
+		t.Errorf("expected the reverse score to give %g, got %g", -diff, reversed)
+	}
+
+	// more games, narrower interval
+	_, wide := Score{Wins: 6, Draws: 2, Losses: 4}.Elo()
+	_, narrow := Score{Wins: 600, Draws: 200, Losses: 400}.Elo()
+	if narrow >= wide {
+		t.Errorf("expected the margin to shrink with more games, got %g and %g", wide, narrow)
+	}
+
+	if diff, _ := (Score{Wins: 5}).Elo(); !math.IsInf(diff, 1) {
+		t.Errorf("expected winning every game to be infinite, got %g", diff)
+	}
+	if _, margin := (Score{Wins: 2, Losses: 1}).Elo(); !math.IsInf(margin, 1) {
+		t.Errorf("expected an infinite margin with so few games, got %g", margin)
+	}
+
+	for _, ratio := range []float64{0.1, 0.5, 0.64, 0.9} {
+		if got := ratioFromElo(eloFromRatio(ratio)); math.Abs(got-ratio) > 1e-9 {
+			t.Errorf("expected %g back from its rating, got %g", ratio, got)
+		}
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	test := SPRT{Elo0: 0, Elo1: 50, Alpha: 0.05, Beta: 0.05}
+	lower, upper := test.Bounds()
+	if math.Abs(lower+2.944) > 0.001 || math.Abs(upper-2.944) > 0.001 {
+		t.Errorf("expected bounds of ±2.944, got %g and %g", lower, upper)
+	}
+
+	tests := []struct {
+		score Score
+		want  SPRTStatus
+	}{
+		{Score{}, SPRTContinue},
+		{Score{Wins: 5, Draws: 5, Losses: 5}, SPRTContinue},
+		{Score{Wins: 300, Draws: 100, Losses: 150}, SPRTAcceptH1},
+		{Score{Wins: 150, Draws: 100, Losses: 300}, SPRTAcceptH0},
+		{Score{Wins: 500, Draws: 500, Losses: 500}, SPRTAcceptH0},
+		// no variance
+		{Score{Wins: 100}, SPRTAcceptH1},
+		{Score{Draws: 2}, SPRTContinue},
+	}
+	for _, tt := range tests {
+		if got := test.Status(tt.score); got != tt.want {
+			t.Errorf("score %v (llr %g): expected %v, got %v", tt.score, test.LLR(tt.score), tt.want, got)
+		}
+	}
+
+	if llr := test.LLR(Score{Wins: 100}); math.IsInf(llr, 0) || math.IsNaN(llr) {
+		t.Errorf("expected a finite llr, got %g", llr)
+	}
+}
+
+func TestSPRTStatusString(t *testing.T) {
+	for status, want := range map[SPRTStatus]string{
+		SPRTContinue: "continue",
+		SPRTAcceptH0: "H0 accepted",
+		SPRTAcceptH1: "H1 accepted",
+	} {
+		if got := status.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package tournament
+
+import (
+	"fmt"
+	"math/rand"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+// Up to n openings of random plies from the initial position of the variant, all leading to
+// different positions where the game isn't over. There may be fewer of them if there aren't
+// that many different positions (or they're hard to find at random).
+func RandomOpenings(v c.Variant, n int, plies int, r *rand.Rand) [][]c.Ply {
+	seen := make(map[uint64]bool)
+	var openings [][]c.Ply
+	for tries := 0; len(openings) < n && tries < 100*n; tries++ {
+		g := c.NewCustomGame(v, nil, pdn.FirstPlayer(v))
+		var opening []c.Ply
+		for len(opening) < plies && !g.Result().Over() {
+			ps := g.Plies()
+			p := ps[r.Intn(len(ps))]
+			g.DoPly(p)
+			opening = append(opening, p)
+		}
+		if g.Result().Over() || seen[g.Hash()] {
+			continue
+		}
+		seen[g.Hash()] = true
+		openings = append(openings, opening)
+	}
+	return openings
+}
+
+// The first plies of each game as openings, skipping the games that don't start
+// from the initial position of the variant or are shorter than that
+func OpeningsFromPDN(games []*pdn.Game, v c.Variant, plies int) ([][]c.Ply, error) {
+	initial := c.NewCustomGame(v, nil, pdn.FirstPlayer(v))
+	var openings [][]c.Ply
+	for i, game := range games {
+		start, err := game.Start()
+		if err != nil {
+			return nil, fmt.Errorf("tournament: game %d: %w", i+1, err)
+		}
+		if start.Variant().Name != v.Name || !start.Board().Equals(initial.Board()) || start.ToPlay() != initial.ToPlay() {
+			continue
+		}
+		_, ps, err := game.Replay()
+		if err != nil {
+			return nil, fmt.Errorf("tournament: game %d: %w", i+1, err)
+		}
+		if len(ps) < plies {
+			continue
+		}
+		openings = append(openings, ps[:plies])
+	}
+	return openings, nil
+}
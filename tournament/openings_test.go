@@ -0,0 +1,79 @@
+package tournament
+
+import (
+	"math/rand"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+func TestRandomOpenings(t *testing.T) {
+	v := c.BrazilianVariant
+	openings := RandomOpenings(v, 10, 4, rand.New(rand.NewSource(1)))
+	if len(openings) != 10 {
+		t.Fatalf("expected 10 openings, got %d", len(openings))
+	}
+	seen := make(map[uint64]bool)
+	for _, opening := range openings {
+		if len(opening) != 4 {
+			t.Errorf("expected 4 plies, got %d", len(opening))
+		}
+		g := c.NewCustomGame(v, nil, pdn.FirstPlayer(v))
+		for _, p := range opening {
+			if !legal(g, p) {
+				t.Fatalf("illegal ply %v in opening %v", p, opening)
+			}
+			g.DoPly(p)
+		}
+		if seen[g.Hash()] {
+			t.Errorf("opening %v leads to a repeated position", opening)
+		}
+		seen[g.Hash()] = true
+	}
+
+	// only 7 plies from the initial position
+	if openings := RandomOpenings(v, 20, 1, rand.New(rand.NewSource(1))); len(openings) != 7 {
+		t.Errorf("expected 7 different openings of a single ply, got %d", len(openings))
+	}
+}
+
+func TestOpeningsFromPDN(t *testing.T) {
+	v := c.BrazilianVariant
+	r := rand.New(rand.NewSource(1))
+	var games []*pdn.Game
+	for _, opening := range RandomOpenings(v, 3, 6, r) {
+		pg, err := pdn.FromPlies(c.NewCustomGame(v, nil, pdn.FirstPlayer(v)), opening)
+		if err != nil {
+			t.Fatal(err)
+		}
+		games = append(games, pg)
+	}
+	short, _ := pdn.FromPlies(c.NewCustomGame(v, nil, pdn.FirstPlayer(v)), RandomOpenings(v, 1, 2, r)[0])
+	fromFEN, _ := pdn.FromPlies(c.NewCustomGame(v, c.DecodeBoard(".\n.\n.\n...o\n..x"), c.WhiteColor), nil)
+	american, _ := pdn.FromPlies(c.NewCustomGame(c.AmericanVariant, nil, c.BlackColor), RandomOpenings(c.AmericanVariant, 1, 6, r)[0])
+	games = append(games, short, fromFEN, american)
+
+	openings, err := OpeningsFromPDN(games, v, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(openings) != 3 {
+		t.Fatalf("expected 3 openings, got %d", len(openings))
+	}
+	for i, opening := range openings {
+		_, plies, _ := games[i].Replay()
+		if !c.PliesEquals(opening, plies[:4]) {
+			t.Errorf("expected opening %d to be the first plies of its game", i)
+		}
+	}
+
+	invalid := []*pdn.Game{{Tags: []pdn.Tag{{Name: "GameType", Value: "99"}}}}
+	if _, err := OpeningsFromPDN(invalid, v, 4); err == nil {
+		t.Errorf("expected an error for an unsupported game type")
+	}
+	illegal := []*pdn.Game{{Tags: games[0].Tags, Moves: []pdn.Move{{Squares: []int{1, 2}}}}}
+	if _, err := OpeningsFromPDN(illegal, v, 4); err == nil {
+		t.Errorf("expected an error for an illegal move")
+	}
+}
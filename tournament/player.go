@@ -0,0 +1,174 @@
+package tournament
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/mcts"
+	"github.com/luc527/go_checkers/minimax"
+)
+
+// Options of the player specs for each searcher
+var searcherOptions = map[string][]string{
+	"minimax":  {"depth", "time", "heuristic", "randomize"},
+	"parallel": {"depth", "time", "heuristic", "workers"},
+	"mcts":     {"iterations", "time", "heuristic"},
+	"level":    {"level", "heuristic", "seed"},
+}
+
+const defaultDepth = 4
+
+// Makes a player out of a spec of comma separated options, e.g. "name=deep,depth=8,heuristic=Positional".
+// The searcher option is one of minimax (the default), parallel, mcts or level, each taking some of the options:
+//
+//	depth      search depth of minimax and parallel, 4 if neither it nor time is given
+//	time       time limit, e.g. 500ms, of minimax, parallel and mcts
+//	heuristic  name of the heuristic (see minimax.HeuristicFromString), WeightedCount by default
+//	randomize  true or false, whether minimax plays plies that look equally good in random order
+//	workers    number of threads of parallel
+//	iterations number of iterations of mcts
+//	level      difficulty level of level
+//	seed       seed of the random choices of level, making its games repeatable when they're played one at a time
+//
+// The name is the spec itself if not given.
+func ParsePlayer(spec string) (Player, error) {
+	opts := make(map[string]string)
+	for _, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return Player{}, fmt.Errorf("tournament: invalid option %q in player %q", field, spec)
+		}
+		opts[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	name, searcher := spec, "minimax"
+	if s, ok := opts["name"]; ok {
+		name = s
+		delete(opts, "name")
+	}
+	if s, ok := opts["searcher"]; ok {
+		searcher = s
+		delete(opts, "searcher")
+	}
+	allowed, ok := searcherOptions[searcher]
+	if !ok {
+		return Player{}, fmt.Errorf("tournament: unknown searcher %q in player %q", searcher, spec)
+	}
+	for key := range opts {
+		found := false
+		for _, a := range allowed {
+			found = found || a == key
+		}
+		if !found {
+			return Player{}, fmt.Errorf("tournament: option %q not allowed for searcher %s in player %q", key, searcher, spec)
+		}
+	}
+
+	p := playerSpec{opts: opts}
+	h := minimax.Heuristic(minimax.WeightedCountHeuristic)
+	if s, ok := opts["heuristic"]; ok {
+		if h = minimax.HeuristicFromString(s); h == nil {
+			return Player{}, fmt.Errorf("tournament: unknown heuristic %q in player %q", s, spec)
+		}
+	}
+	depth := p.int("depth")
+	tlim := p.duration("time")
+	randomize := p.bool("randomize")
+	workers := p.int("workers")
+	iterations := p.int("iterations")
+	level := p.int("level")
+	seed := p.int("seed")
+	if p.err != nil {
+		return Player{}, fmt.Errorf("tournament: %w in player %q", p.err, spec)
+	}
+	if depth == 0 && tlim == 0 {
+		depth = defaultDepth
+	}
+
+	var newSearcher func(color c.Color) minimax.Searcher
+	switch searcher {
+	case "minimax":
+		newSearcher = func(color c.Color) minimax.Searcher {
+			if depth > 0 {
+				return minimax.DepthLimitedSearcher{ToMax: color, Heuristic: h, DepthLimit: depth, Randomize: randomize}
+			}
+			return minimax.TimeLimitedSearcher{ToMax: color, Heuristic: h, TimeLimit: tlim, Randomize: randomize}
+		}
+	case "parallel":
+		newSearcher = func(color c.Color) minimax.Searcher {
+			return minimax.ParallelSearcher{ToMax: color, Heuristic: h, DepthLimit: depth, TimeLimit: tlim, Workers: workers}
+		}
+	case "mcts":
+		newSearcher = func(c.Color) minimax.Searcher {
+			s := mcts.Searcher{Iterations: iterations, TimeLimit: tlim}
+			if _, ok := opts["heuristic"]; ok {
+				s.Heuristic = h
+			}
+			return s
+		}
+	case "level":
+		// each searcher gets a source of its own, seeded by the one of the player
+		var mu sync.Mutex
+		var seeds *rand.Rand
+		if _, ok := opts["seed"]; ok {
+			seeds = rand.New(rand.NewSource(int64(seed)))
+		}
+		newSearcher = func(c.Color) minimax.Searcher {
+			s := minimax.LevelSearcher{Level: level, Heuristic: h}
+			if seeds != nil {
+				mu.Lock()
+				s.Rand = rand.New(rand.NewSource(seeds.Int63()))
+				mu.Unlock()
+			}
+			return s
+		}
+	}
+	return Player{Name: name, New: newSearcher}, nil
+}
+
+// Parses the options of a spec, keeping the first error
+type playerSpec struct {
+	opts map[string]string
+	err  error
+}
+
+func (p *playerSpec) int(key string) int {
+	s, ok := p.opts[key]
+	if !ok || p.err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		p.err = fmt.Errorf("invalid %s %q", key, s)
+	}
+	return n
+}
+
+func (p *playerSpec) duration(key string) time.Duration {
+	s, ok := p.opts[key]
+	if !ok || p.err != nil {
+		return 0
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		p.err = fmt.Errorf("invalid %s %q", key, s)
+	}
+	return d
+}
+
+func (p *playerSpec) bool(key string) bool {
+	s, ok := p.opts[key]
+	if !ok || p.err != nil {
+		return false
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		p.err = fmt.Errorf("invalid %s %q", key, s)
+	}
+	return b
+}
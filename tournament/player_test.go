@@ -0,0 +1,147 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/mcts"
+	"github.com/luc527/go_checkers/minimax"
+)
+
+func TestParsePlayer(t *testing.T) {
+	tests := []struct {
+		spec string
+		name string
+		want minimax.Searcher
+	}{
+		{
+			"depth=3",
+			"depth=3",
+			minimax.DepthLimitedSearcher{ToMax: c.BlackColor, Heuristic: minimax.WeightedCountHeuristic, DepthLimit: 3},
+		},
+		{
+			"name=default",
+			"default",
+			minimax.DepthLimitedSearcher{ToMax: c.BlackColor, Heuristic: minimax.WeightedCountHeuristic, DepthLimit: defaultDepth},
+		},
+		{
+			"name=timed, time=200ms, heuristic=UnweightedCount, randomize=true",
+			"timed",
+			minimax.TimeLimitedSearcher{ToMax: c.BlackColor, Heuristic: minimax.UnweightedCountHeuristic, TimeLimit: 200 * time.Millisecond, Randomize: true},
+		},
+		{
+			"searcher=parallel,depth=5,workers=2",
+			"searcher=parallel,depth=5,workers=2",
+			minimax.ParallelSearcher{ToMax: c.BlackColor, Heuristic: minimax.WeightedCountHeuristic, DepthLimit: 5, Workers: 2},
+		},
+		{
+			"searcher=mcts,iterations=100",
+			"searcher=mcts,iterations=100",
+			mcts.Searcher{Iterations: 100},
+		},
+		{
+			"searcher=mcts,time=1s,heuristic=UnweightedCount",
+			"searcher=mcts,time=1s,heuristic=UnweightedCount",
+			mcts.Searcher{TimeLimit: time.Second, Heuristic: minimax.UnweightedCountHeuristic},
+		},
+		{
+			"searcher=level,level=3",
+			"searcher=level,level=3",
+			minimax.LevelSearcher{Level: 3, Heuristic: minimax.WeightedCountHeuristic},
+		},
+	}
+	for _, tt := range tests {
+		p, err := ParsePlayer(tt.spec)
+		if err != nil {
+			t.Errorf("%q: %v", tt.spec, err)
+			continue
+		}
+		if p.Name != tt.name {
+			t.Errorf("%q: expected name %q, got %q", tt.spec, tt.name, p.Name)
+		}
+		// functions can't be compared, only whether they're nil
+		got := p.New(c.BlackColor)
+		if !reflect.DeepEqual(withoutHeuristic(got), withoutHeuristic(tt.want)) || heuristicName(got) != heuristicName(tt.want) {
+			t.Errorf("%q: expected %#v, got %#v", tt.spec, tt.want, got)
+		}
+	}
+}
+
+func withoutHeuristic(s minimax.Searcher) minimax.Searcher {
+	switch s := s.(type) {
+	case minimax.DepthLimitedSearcher:
+		s.Heuristic = nil
+		return s
+	case minimax.TimeLimitedSearcher:
+		s.Heuristic = nil
+		return s
+	case minimax.ParallelSearcher:
+		s.Heuristic = nil
+		return s
+	case mcts.Searcher:
+		s.Heuristic = nil
+		return s
+	case minimax.LevelSearcher:
+		s.Heuristic = nil
+		return s
+	}
+	return s
+}
+
+func heuristicName(s minimax.Searcher) string {
+	h := reflect.ValueOf(s).FieldByName("Heuristic").Interface().(minimax.Heuristic)
+	if h == nil {
+		return ""
+	}
+	return h.String()
+}
+
+func TestParsePlayerSeed(t *testing.T) {
+	// the same seed makes the same searchers, in order
+	var players [2]Player
+	for i := range players {
+		p, err := ParsePlayer("searcher=level,level=3,seed=1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		players[i] = p
+	}
+	var draws [2][]int64
+	for i, p := range players {
+		for j := 0; j < 3; j++ {
+			s := p.New(c.WhiteColor).(minimax.LevelSearcher)
+			if s.Rand == nil {
+				t.Fatalf("expected a seeded searcher to have a source")
+			}
+			draws[i] = append(draws[i], s.Rand.Int63())
+		}
+	}
+	if !reflect.DeepEqual(draws[0], draws[1]) {
+		t.Errorf("expected the same draws from the same seed, got %v and %v", draws[0], draws[1])
+	}
+	if draws[0][0] == draws[0][1] {
+		t.Errorf("expected each searcher to get a source of its own")
+	}
+}
+
+func TestParsePlayerErrors(t *testing.T) {
+	for _, spec := range []string{
+		"depth",
+		"searcher=alphabeta",
+		"searcher=mcts,depth=3",
+		"heuristic=Unknown",
+		"depth=three",
+		"depth=-1",
+		"time=soon",
+		"time=-1s",
+		"randomize=maybe",
+		"seed=1",
+		"searcher=level,seed=x",
+	} {
+		if _, err := ParsePlayer(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package tournament
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+)
+
+func formatElo(diff, margin float64) string {
+	if math.IsInf(diff, 0) {
+		return fmt.Sprintf("%+.0f", diff)
+	}
+	if math.IsInf(margin, 0) {
+		return fmt.Sprintf("%+.0f ± inf", diff)
+	}
+	return fmt.Sprintf("%+.0f ± %.0f", diff, margin)
+}
+
+// Writes a table of the score of each player against the whole field,
+// and one of each pairing, with the Elo rating difference of the first player
+func (r *Results) Report(w io.Writer, sprt *SPRT) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "player\tgames\tscore\tpoints\telo")
+	for i, s := range r.Totals() {
+		diff, margin := s.Elo()
+		fmt.Fprintf(tw, "%s\t%d\t%v\t%.1f%%\t%s\n", r.Players[i], s.Games(), s, 100*s.Ratio(), formatElo(diff, margin))
+	}
+
+	fmt.Fprintln(tw)
+	header := "pairing\tgames\tscore\tpoints\telo"
+	if sprt != nil {
+		header += "\tllr\tsprt"
+	}
+	fmt.Fprintln(tw, header)
+	for _, p := range r.Pairings {
+		s := p.Score
+		diff, margin := s.Elo()
+		fmt.Fprintf(tw, "%s vs %s\t%d\t%v\t%.1f%%\t%s", r.Players[p.Players[0]], r.Players[p.Players[1]],
+			s.Games(), s, 100*s.Ratio(), formatElo(diff, margin))
+		if sprt != nil {
+			lower, upper := sprt.Bounds()
+			fmt.Fprintf(tw, "\t%.2f (%.2f, %.2f)\t%v", sprt.LLR(s), lower, upper, p.SPRT)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
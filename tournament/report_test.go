@@ -0,0 +1,49 @@
+package tournament
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	res := &Results{
+		Players: []string{"new", "old", "other"},
+		Pairings: []PairingResult{
+			{Players: [2]int{0, 1}, Score: Score{Wins: 30, Draws: 40, Losses: 20}, SPRT: SPRTAcceptH1},
+			{Players: [2]int{0, 2}, Score: Score{Wins: 3}},
+		},
+	}
+
+	var sb strings.Builder
+	if err := res.Report(&sb, nil); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{"new vs old", "+33 =40 -20", "+20 =40 -30", "+0 =0 -3", "+39 ± ", "-Inf", "+Inf"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the report:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "sprt") {
+		t.Errorf("expected no test columns without a test:\n%s", out)
+	}
+
+	sb.Reset()
+	if err := res.Report(&sb, &SPRT{Elo0: 0, Elo1: 20, Alpha: 0.05, Beta: 0.05}); err != nil {
+		t.Fatal(err)
+	}
+	out = sb.String()
+	for _, want := range []string{"llr", "H1 accepted", "(-2.94, 2.94)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the report:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatElo(t *testing.T) {
+	score := Score{Wins: 2, Losses: 1}
+	diff, margin := score.Elo()
+	if got := formatElo(diff, margin); got != "+120 ± inf" {
+		t.Errorf("expected an infinite margin, got %q", got)
+	}
+}
@@ -0,0 +1,334 @@
+// Package tournament plays matches between searchers to compare their strength:
+// round-robin or gauntlet tournaments from varied openings, with each opening played
+// twice so both players get each side, Elo rating differences and SPRT stopping.
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+const DefaultMaxPlies = 300
+
+// A Player makes the searcher it plays with in each game, since some of them
+// depend on the color played (e.g. minimax.DepthLimitedSearcher.ToMax)
+// and some aren't safe for concurrent use
+type Player struct {
+	Name string
+	New  func(color c.Color) minimax.Searcher
+}
+
+// Pairs of players (indices) that play against each other, every player against each other one
+func RoundRobin(players int) [][2]int {
+	var pairings [][2]int
+	for i := 0; i < players; i++ {
+		for j := i + 1; j < players; j++ {
+			pairings = append(pairings, [2]int{i, j})
+		}
+	}
+	return pairings
+}
+
+// Pairs of players (indices) that play against each other, the first player against each other one
+func Gauntlet(players int) [][2]int {
+	var pairings [][2]int
+	for i := 1; i < players; i++ {
+		pairings = append(pairings, [2]int{0, i})
+	}
+	return pairings
+}
+
+// Ways a game can end other than by the rules
+const (
+	// It went on for too long, and is counted as a draw
+	AdjudicatedTermination = "adjudicated"
+	// The player to move didn't find a legal ply, and loses
+	ForfeitTermination = "forfeit"
+)
+
+type Game struct {
+	// Position of the game in the order of the tournament
+	Round        int
+	White, Black string
+	// The initial position of the variant
+	Start *c.Game
+	// Plies played from the start, including the ones of the opening
+	Plies  []c.Ply
+	Result c.GameResult
+	// Empty when the game ended by the rules
+	Termination string
+}
+
+// The game as PDN, with the players' names in the tags
+func (g Game) PDN() (*pdn.Game, error) {
+	pg, err := pdn.FromPlies(g.Start, g.Plies)
+	if err != nil {
+		return nil, err
+	}
+	pg.SetTag("Round", strconv.Itoa(g.Round+1))
+	pg.SetTag("White", g.White)
+	pg.SetTag("Black", g.Black)
+	if g.Termination != "" {
+		pg.SetTag("Termination", g.Termination)
+		switch g.Result {
+		case c.WhiteWonResult:
+			pg.Result = pdn.WhiteWinResult
+		case c.BlackWonResult:
+			pg.Result = pdn.BlackWinResult
+		default:
+			pg.Result = pdn.DrawResultText
+		}
+		pg.SetTag("Result", pg.Result)
+	}
+	return pg, nil
+}
+
+// Plays a game between the players from the opening, played from the initial position of the variant.
+// Games longer than maxPlies (DefaultMaxPlies if zero) are adjudicated as draws.
+// Returns an error if a ply of the opening isn't legal or the context is done before the game ends.
+func Play(ctx context.Context, v c.Variant, white, black Player, opening []c.Ply, maxPlies int) (Game, error) {
+	if maxPlies <= 0 {
+		maxPlies = DefaultMaxPlies
+	}
+	start := c.NewCustomGame(v, nil, pdn.FirstPlayer(v))
+	game := Game{White: white.Name, Black: black.Name, Start: start}
+	g := start.Copy()
+	for _, p := range opening {
+		if !legal(g, p) {
+			return game, fmt.Errorf("tournament: illegal ply %v in the opening", p)
+		}
+		g.DoPly(p)
+		game.Plies = append(game.Plies, p)
+	}
+
+	var searchers [2]minimax.Searcher
+	searchers[c.WhiteColor] = white.New(c.WhiteColor)
+	searchers[c.BlackColor] = black.New(c.BlackColor)
+	for !g.Result().Over() {
+		if len(game.Plies) >= maxPlies {
+			game.Result = c.DrawResult
+			game.Termination = AdjudicatedTermination
+			return game, nil
+		}
+		p := searchers[g.ToPlay()].SearchContext(ctx, g)
+		if err := ctx.Err(); err != nil {
+			return game, err
+		}
+		if !legal(g, p) {
+			game.Result = c.WhiteWonResult
+			if g.ToPlay() == c.WhiteColor {
+				game.Result = c.BlackWonResult
+			}
+			game.Termination = ForfeitTermination
+			return game, nil
+		}
+		g.DoPly(p)
+		game.Plies = append(game.Plies, p)
+	}
+	game.Result = g.Result()
+	return game, nil
+}
+
+func legal(g *c.Game, p c.Ply) bool {
+	for _, q := range g.Plies() {
+		if q.Equals(p) {
+			return true
+		}
+	}
+	return false
+}
+
+type Tournament struct {
+	Variant c.Variant
+	Players []Player
+	// Made with RoundRobin or Gauntlet, for example
+	Pairings [][2]int
+	// Games of each pairing, rounded up to an even number so each opening is played with both colors
+	GamesPerPairing int
+	// Plies from the initial position the games start with, taken in turns.
+	// Without them, every game starts from the initial position.
+	Openings [][]c.Ply
+	// DefaultMaxPlies if zero
+	MaxPlies int
+	// Optional, a pairing stops playing once the test tells the first player
+	// is stronger than the second or not
+	SPRT *SPRT
+	// Games played at the same time, 1 if zero
+	Workers int
+	// Optional, called after each game (not concurrently)
+	OnGame func(Game)
+}
+
+type PairingResult struct {
+	Players [2]int
+	// From the first player's point of view
+	Score Score
+	// SPRTContinue without a test
+	SPRT SPRTStatus
+}
+
+type Results struct {
+	Players  []string
+	Pairings []PairingResult
+	// Sorted by round
+	Games []Game
+}
+
+// Score of each player against all of their opponents
+func (r *Results) Totals() []Score {
+	totals := make([]Score, len(r.Players))
+	for _, p := range r.Pairings {
+		a, b := &totals[p.Players[0]], &totals[p.Players[1]]
+		a.Wins, a.Draws, a.Losses = a.Wins+p.Score.Wins, a.Draws+p.Score.Draws, a.Losses+p.Score.Losses
+		b.Wins, b.Draws, b.Losses = b.Wins+p.Score.Losses, b.Draws+p.Score.Draws, b.Losses+p.Score.Wins
+	}
+	return totals
+}
+
+// The games as PDN
+func (r *Results) PDN() ([]*pdn.Game, error) {
+	games := make([]*pdn.Game, len(r.Games))
+	for i, g := range r.Games {
+		pg, err := g.PDN()
+		if err != nil {
+			return nil, fmt.Errorf("tournament: round %d: %w", g.Round+1, err)
+		}
+		games[i] = pg
+	}
+	return games, nil
+}
+
+type job struct {
+	round, pairing int
+	swap           bool
+	opening        []c.Ply
+}
+
+func (t *Tournament) jobs() []job {
+	games := t.GamesPerPairing + t.GamesPerPairing%2
+	var jobs []job
+	// interleaved, so pairings stopped by the SPRT don't leave the others to play alone at the end
+	for i := 0; i < games; i++ {
+		for pairing := range t.Pairings {
+			var opening []c.Ply
+			if len(t.Openings) > 0 {
+				opening = t.Openings[(i/2)%len(t.Openings)]
+			}
+			jobs = append(jobs, job{len(jobs), pairing, i%2 == 1, opening})
+		}
+	}
+	return jobs
+}
+
+// Plays the games of the tournament. If the context is done before it ends,
+// it returns the results of the games finished so far along with the error.
+func (t *Tournament) Run(ctx context.Context) (*Results, error) {
+	for _, p := range t.Pairings {
+		for _, i := range p {
+			if i < 0 || i >= len(t.Players) || p[0] == p[1] {
+				return nil, fmt.Errorf("tournament: invalid pairing %v of %d players", p, len(t.Players))
+			}
+		}
+	}
+	workers := t.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	results := &Results{}
+	for _, p := range t.Players {
+		results.Players = append(results.Players, p.Name)
+	}
+	for _, p := range t.Pairings {
+		results.Pairings = append(results.Pairings, PairingResult{Players: p})
+	}
+
+	var mu sync.Mutex
+	// whether the pairing should keep playing
+	playing := func(pairing int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return results.Pairings[pairing].SPRT == SPRTContinue
+	}
+	record := func(j job, g Game) {
+		mu.Lock()
+		defer mu.Unlock()
+		pr := &results.Pairings[j.pairing]
+		first := c.WhiteColor
+		if j.swap {
+			first = c.BlackColor
+		}
+		switch {
+		case g.Result == c.DrawResult:
+			pr.Score.Draws++
+		case (g.Result == c.WhiteWonResult) == (first == c.WhiteColor):
+			pr.Score.Wins++
+		default:
+			pr.Score.Losses++
+		}
+		if t.SPRT != nil && pr.SPRT == SPRTContinue {
+			pr.SPRT = t.SPRT.Status(pr.Score)
+		}
+		results.Games = append(results.Games, g)
+		if t.OnGame != nil {
+			t.OnGame(g)
+		}
+	}
+
+	jobs := make(chan job)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if !playing(j.pairing) {
+					continue
+				}
+				pair := t.Pairings[j.pairing]
+				white, black := t.Players[pair[0]], t.Players[pair[1]]
+				if j.swap {
+					white, black = black, white
+				}
+				g, err := Play(ctx, t.Variant, white, black, j.opening, t.MaxPlies)
+				if err != nil {
+					errs <- err
+					return
+				}
+				g.Round = j.round
+				record(j, g)
+			}
+		}()
+	}
+
+	var err error
+sending:
+	for _, j := range t.jobs() {
+		select {
+		case jobs <- j:
+		case err = <-errs:
+			break sending
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+
+	sort.Slice(results.Games, func(i, j int) bool {
+		return results.Games[i].Round < results.Games[j].Round
+	})
+	return results, err
+}
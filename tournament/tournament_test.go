@@ -0,0 +1,226 @@
+package tournament
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+// Never finds a ply
+type nilSearcher struct{}
+
+func (nilSearcher) Search(g *c.Game) c.Ply {
+	return nil
+}
+
+func (nilSearcher) SearchContext(ctx context.Context, g *c.Game) c.Ply {
+	return nil
+}
+
+func depthPlayer(name string, depth int) Player {
+	return Player{name, func(color c.Color) minimax.Searcher {
+		return minimax.DepthLimitedSearcher{ToMax: color, Heuristic: minimax.WeightedCountHeuristic, DepthLimit: depth, Randomize: true}
+	}}
+}
+
+var forfeitPlayer = Player{"forfeit", func(c.Color) minimax.Searcher { return nilSearcher{} }}
+
+func TestPairings(t *testing.T) {
+	if got, want := RoundRobin(3), [][2]int{{0, 1}, {0, 2}, {1, 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected round robin pairings %v, got %v", want, got)
+	}
+	if got, want := Gauntlet(3), [][2]int{{0, 1}, {0, 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected gauntlet pairings %v, got %v", want, got)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	ctx := context.Background()
+	v := c.AmericanVariant
+	opening := RandomOpenings(v, 1, 4, rand.New(rand.NewSource(1)))[0]
+
+	g, err := Play(ctx, v, depthPlayer("a", 1), depthPlayer("b", 1), opening, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Plies) != 10 || g.Result != c.DrawResult || g.Termination != AdjudicatedTermination {
+		t.Errorf("expected a draw adjudicated after 10 plies, got %d plies, %v, %q", len(g.Plies), g.Result, g.Termination)
+	}
+	if !c.PliesEquals(g.Plies[:4], opening) {
+		t.Errorf("expected the game to start with the opening")
+	}
+	pg, err := g.PDN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tag, want := range map[string]string{"White": "a", "Black": "b", "Round": "1", "Result": pdn.DrawResultText, "Termination": AdjudicatedTermination} {
+		if got, _ := pg.Tag(tag); got != want {
+			t.Errorf("expected tag %s %q, got %q", tag, want, got)
+		}
+	}
+
+	// not randomized, so the game always ends the same way
+	fixed := Player{"fixed", func(color c.Color) minimax.Searcher {
+		return minimax.DepthLimitedSearcher{ToMax: color, Heuristic: minimax.WeightedCountHeuristic, DepthLimit: 2}
+	}}
+	g, err = Play(ctx, v, fixed, fixed, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Result.Over() || g.Termination != "" {
+		t.Errorf("expected the game to end by the rules, got %v, %q", g.Result, g.Termination)
+	}
+	pg, err = g.PDN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pg.Tag("Termination"); ok {
+		t.Errorf("expected no termination tag")
+	}
+	final, _, err := pg.Replay()
+	if err != nil || final.Result() != g.Result {
+		t.Errorf("expected the PDN to replay to the same result, got %v, %v", final, err)
+	}
+}
+
+func TestPlayForfeit(t *testing.T) {
+	// american: black moves first
+	g, err := Play(context.Background(), c.AmericanVariant, depthPlayer("a", 1), forfeitPlayer, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Result != c.WhiteWonResult || g.Termination != ForfeitTermination || len(g.Plies) != 0 {
+		t.Errorf("expected black to forfeit, got %v, %q after %d plies", g.Result, g.Termination, len(g.Plies))
+	}
+	pg, err := g.PDN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pg.Result != pdn.WhiteWinResult {
+		t.Errorf("expected white to win, got %q", pg.Result)
+	}
+
+	g, _ = Play(context.Background(), c.AmericanVariant, forfeitPlayer, depthPlayer("a", 1), nil, 0)
+	if g.Result != c.BlackWonResult || g.Termination != ForfeitTermination || len(g.Plies) != 1 {
+		t.Errorf("expected white to forfeit, got %v, %q after %d plies", g.Result, g.Termination, len(g.Plies))
+	}
+}
+
+func TestPlayErrors(t *testing.T) {
+	v := c.BrazilianVariant
+	// white moves first, this is a black ply
+	opening := c.NewCustomGame(v, nil, c.BlackColor).Plies()[:1]
+	if _, err := Play(context.Background(), v, depthPlayer("a", 1), depthPlayer("b", 1), opening, 0); err == nil {
+		t.Errorf("expected an error for an illegal opening")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Play(ctx, v, depthPlayer("a", 1), depthPlayer("b", 1), nil, 0); err != context.Canceled {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	v := c.AmericanVariant
+	players := []Player{depthPlayer("a", 1), depthPlayer("b", 2), depthPlayer("c", 1)}
+	var called []int
+	tour := Tournament{
+		Variant:         v,
+		Players:         players,
+		Pairings:        RoundRobin(len(players)),
+		GamesPerPairing: 3,
+		Openings:        RandomOpenings(v, 2, 2, rand.New(rand.NewSource(1))),
+		MaxPlies:        60,
+		Workers:         2,
+		OnGame:          func(g Game) { called = append(called, g.Round) },
+	}
+	res, err := tour.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Games) != 12 || len(called) != 12 {
+		t.Fatalf("expected 12 games (rounded up to 4 per pairing), got %d, called %d times", len(res.Games), len(called))
+	}
+	for i, g := range res.Games {
+		if g.Round != i {
+			t.Errorf("expected the games sorted by round, got round %d at %d", g.Round, i)
+		}
+		opening := tour.Openings[(i/len(tour.Pairings)/2)%len(tour.Openings)]
+		if !c.PliesEquals(g.Plies[:len(opening)], opening) {
+			t.Errorf("round %d: expected the game to start with opening %v", g.Round, opening)
+		}
+	}
+	// each opening is played with both colors
+	if res.Games[0].White != res.Games[3].Black || res.Games[0].Black != res.Games[3].White {
+		t.Errorf("expected the players to swap colors")
+	}
+
+	games := 0
+	for _, s := range res.Totals() {
+		games += s.Games()
+	}
+	if games != 24 {
+		t.Errorf("expected 24 games in the totals (each counted twice), got %d", games)
+	}
+	for _, p := range res.Pairings {
+		if p.Score.Games() != 4 || p.SPRT != SPRTContinue {
+			t.Errorf("pairing %v: expected 4 games and no test, got %v, %v", p.Players, p.Score, p.SPRT)
+		}
+	}
+
+	pgs, err := res.PDN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if white, _ := pgs[1].Tag("White"); white != res.Games[1].White {
+		t.Errorf("expected white %q in the PDN, got %q", res.Games[1].White, white)
+	}
+}
+
+func TestRunSPRT(t *testing.T) {
+	tour := Tournament{
+		Variant:         c.AmericanVariant,
+		Players:         []Player{depthPlayer("a", 1), forfeitPlayer},
+		Pairings:        [][2]int{{0, 1}},
+		GamesPerPairing: 100,
+		SPRT:            &SPRT{Elo0: 0, Elo1: 50, Alpha: 0.05, Beta: 0.05},
+	}
+	res, err := tour.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := res.Pairings[0]
+	if p.SPRT != SPRTAcceptH1 || p.Score.Games() >= 100 || p.Score.Losses > 0 {
+		t.Errorf("expected the test to stop early accepting H1, got %v after %v", p.SPRT, p.Score)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tour := Tournament{
+		Variant:  c.AmericanVariant,
+		Players:  []Player{depthPlayer("a", 1), depthPlayer("b", 1)},
+		Pairings: [][2]int{{0, 2}},
+	}
+	if _, err := tour.Run(context.Background()); err == nil {
+		t.Errorf("expected an error for a pairing with a missing player")
+	}
+	tour.Pairings = [][2]int{{1, 1}}
+	if _, err := tour.Run(context.Background()); err == nil {
+		t.Errorf("expected an error for a player paired with itself")
+	}
+
+	tour.Pairings = Gauntlet(2)
+	tour.GamesPerPairing = 10
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := tour.Run(ctx)
+	if err != context.Canceled || len(res.Games) != 0 {
+		t.Errorf("expected the context error and no games, got %v, %d games", err, len(res.Games))
+	}
+}
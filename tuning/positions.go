@@ -0,0 +1,123 @@
+// Package tuning finds the weights of minimax.Weights that best predict how
+// games end (Texel tuning): the static evaluation of each position, through a
+// sigmoid, should be close to the result of the game it was taken from.
+package tuning
+
+import (
+	"fmt"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+// A Position is a position of a game, with how the game ended
+type Position struct {
+	// The terms of the evaluation of the position for white (see minimax.Features)
+	Features minimax.Weights
+	// 1 if white won the game, 0 if black won, 0.5 for a draw
+	Result float64
+}
+
+func resultScore(r c.GameResult) (float64, bool) {
+	switch r {
+	case c.WhiteWonResult:
+		return 1, true
+	case c.BlackWonResult:
+		return 0, true
+	case c.DrawResult:
+		return 0.5, true
+	default:
+		return 0, false
+	}
+}
+
+func isCapture(p c.Ply) bool {
+	for _, ins := range p {
+		if ins.Type() == c.CaptureInstruction {
+			return true
+		}
+	}
+	return false
+}
+
+// Positions of the game played from start, skipping the first plies (still in the opening)
+// and the ones where the player to move can capture, since their static evaluation doesn't
+// account for the capture. Games that didn't end have no positions.
+// Returns an error if one of the plies can't be played.
+func PositionsFromGame(start *c.Game, plies []c.Ply, result c.GameResult, skip int) ([]Position, error) {
+	score, ok := resultScore(result)
+	if !ok {
+		return nil, nil
+	}
+	var positions []Position
+	g := start.Copy()
+	for i := 0; ; i++ {
+		if i >= skip && !g.Result().Over() {
+			quiet := true
+			for _, p := range g.Plies() {
+				if isCapture(p) {
+					quiet = false
+					break
+				}
+			}
+			if quiet {
+				positions = append(positions, Position{minimax.Features(g.Board(), c.WhiteColor), score})
+			}
+		}
+		if i >= len(plies) {
+			break
+		}
+		if _, err := g.DoPly(plies[i]); err != nil {
+			return nil, fmt.Errorf("tuning: ply %d: %w", i+1, err)
+		}
+	}
+	return positions, nil
+}
+
+var pdnResults = map[string]c.GameResult{
+	pdn.WhiteWinResult: c.WhiteWonResult,
+	pdn.BlackWinResult: c.BlackWonResult,
+	pdn.DrawResultText: c.DrawResult,
+}
+
+// Same as PositionsFromGame, for a game of a PDN file. Games without a result have no positions.
+func PositionsFromPDN(game *pdn.Game, skip int) ([]Position, error) {
+	result, ok := pdnResults[game.Result]
+	if !ok {
+		return nil, nil
+	}
+	start, err := game.Start()
+	if err != nil {
+		return nil, fmt.Errorf("tuning: %w", err)
+	}
+	_, plies, err := game.Replay()
+	if err != nil {
+		return nil, fmt.Errorf("tuning: %w", err)
+	}
+	return PositionsFromGame(start, plies, result, skip)
+}
+
+// Plays a game between the searchers from the initial position of the variant.
+// If it doesn't end within maxPlies plies, or a searcher doesn't find a ply that
+// can be played, its result is core.PlayingResult.
+func SelfPlay(v c.Variant, white, black minimax.Searcher, maxPlies int) (*c.Game, []c.Ply, c.GameResult) {
+	start := c.NewCustomGame(v, nil, pdn.FirstPlayer(v))
+	g := start.Copy()
+	var plies []c.Ply
+	for len(plies) < maxPlies && !g.Result().Over() {
+		s := white
+		if g.ToPlay() == c.BlackColor {
+			s = black
+		}
+		p := s.Search(g)
+		if p == nil {
+			return start, plies, c.PlayingResult
+		}
+		if _, err := g.DoPly(p); err != nil {
+			return start, plies, c.PlayingResult
+		}
+		plies = append(plies, p)
+	}
+	return start, plies, g.Result()
+}
@@ -0,0 +1,125 @@
+package tuning
+
+import (
+	"testing"
+
+	c "github.com/luc527/go_checkers/core"
+	"github.com/luc527/go_checkers/minimax"
+	"github.com/luc527/go_checkers/pdn"
+)
+
+// 3. ... 22-18 lets black capture, and 4. 15x22 makes white capture back
+const sampleGame = `[GameType "21"]
+1. 11-15 24-20 2. 8-11 27-24 3. 3-8 22-18 4. 15x22 25x18 1-0`
+
+func readGame(t *testing.T, s string) *pdn.Game {
+	t.Helper()
+	games, err := pdn.ReadString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return games[0]
+}
+
+func TestPositionsFromPDN(t *testing.T) {
+	positions, err := PositionsFromPDN(readGame(t, sampleGame), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 9 positions, counting the start and the end, without the 2 with captures
+	if len(positions) != 7 {
+		t.Fatalf("expected 7 positions, got %d", len(positions))
+	}
+	for _, p := range positions {
+		if p.Result != 1 {
+			t.Errorf("expected positions of a game won by white, got result %g", p.Result)
+		}
+	}
+	start := c.NewCustomGame(c.AmericanVariant, nil, c.BlackColor)
+	if positions[0].Features != minimax.Features(start.Board(), c.WhiteColor) {
+		t.Errorf("wrong features for the start position %+v", positions[0].Features)
+	}
+
+	if positions, _ := PositionsFromPDN(readGame(t, sampleGame), 4); len(positions) != 3 {
+		t.Errorf("expected 3 positions skipping the first 4 plies, got %d", len(positions))
+	}
+
+	unfinished := readGame(t, `[GameType "21"] 1. 11-15 24-20 *`)
+	if positions, err := PositionsFromPDN(unfinished, 0); err != nil || positions != nil {
+		t.Errorf("expected no positions for a game without a result, got %v, %v", positions, err)
+	}
+
+	for _, s := range []string{
+		`[GameType "21"] [FEN "W:Wxx:B1"] 1. 11-15 1-0`,
+		`[GameType "21"] 1. 11-15 24-20 2. 1-2 1-0`,
+	} {
+		if _, err := PositionsFromPDN(readGame(t, s), 0); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestPositionsFromGameResults(t *testing.T) {
+	start := c.NewGame()
+	for result, want := range map[c.GameResult]float64{c.WhiteWonResult: 1, c.BlackWonResult: 0, c.DrawResult: 0.5} {
+		positions, err := PositionsFromGame(start, nil, result, 0)
+		if err != nil || len(positions) != 1 || positions[0].Result != want {
+			t.Errorf("%v: expected one position with result %g, got %v, %v", result, want, positions, err)
+		}
+	}
+	if positions, err := PositionsFromGame(start, nil, c.PlayingResult, 0); err != nil || positions != nil {
+		t.Errorf("expected no positions for a game that didn't end, got %v, %v", positions, err)
+	}
+	if _, err := PositionsFromGame(start, []c.Ply{start.Plies()[0], nil}, c.WhiteWonResult, 0); err == nil {
+		t.Errorf("expected an error for a ply that can't be played")
+	}
+}
+
+// Returns the last ply after the first few
+type tiredSearcher struct {
+	minimax.DepthLimitedSearcher
+	plies *int
+	last  c.Ply
+}
+
+func (s tiredSearcher) Search(g *c.Game) c.Ply {
+	if *s.plies >= 3 {
+		return s.last
+	}
+	*s.plies++
+	return s.DepthLimitedSearcher.Search(g)
+}
+
+func TestSelfPlay(t *testing.T) {
+	white := minimax.DepthLimitedSearcher{ToMax: c.WhiteColor, Heuristic: minimax.WeightedCountHeuristic, DepthLimit: 2}
+	black := minimax.DepthLimitedSearcher{ToMax: c.BlackColor, Heuristic: minimax.WeightedCountHeuristic, DepthLimit: 1}
+
+	start, plies, result := SelfPlay(c.BrazilianVariant, white, black, 10)
+	if !start.Equals(c.NewGame()) {
+		t.Errorf("expected the game to start from the initial position")
+	}
+	if len(plies) != 10 || result != c.PlayingResult {
+		t.Errorf("expected 10 plies of an unfinished game, got %d and %v", len(plies), result)
+	}
+	g := start.Copy()
+	for _, p := range plies {
+		if _, err := g.DoPly(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a searcher that finds no ply, or one that can't be played, stops the game
+	for _, last := range []c.Ply{nil, {}} {
+		var n int
+		_, plies, result = SelfPlay(c.BrazilianVariant, tiredSearcher{white, &n, last}, black, 100)
+		if len(plies) != 6 || result != c.PlayingResult {
+			t.Errorf("%#v: expected the game to stop after 6 plies, unfinished, got %d and %v", last, len(plies), result)
+		}
+	}
+
+	// long enough to end
+	_, plies, result = SelfPlay(c.BrazilianVariant, white, black, 1000)
+	if !result.Over() || len(plies) >= 1000 {
+		t.Errorf("expected the game to end, got %v after %d plies", result, len(plies))
+	}
+}
@@ -0,0 +1,155 @@
+package tuning
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/luc527/go_checkers/minimax"
+)
+
+// Chance of white winning a game given the evaluation of one of its positions
+func sigmoid(scale, eval float64) float64 {
+	return 1 / (1 + math.Exp(-scale*eval))
+}
+
+// Mean squared error between the results of the positions and what the weights predict
+func Error(w minimax.Weights, scale float64, positions []Position) float64 {
+	if len(positions) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, p := range positions {
+		d := p.Result - sigmoid(scale, w.Dot(p.Features))
+		sum += d * d
+	}
+	return sum / float64(len(positions))
+}
+
+// Scale of the sigmoid with the least error for the weights, between 0 and maxScale
+func FitScale(w minimax.Weights, positions []Position) float64 {
+	const maxScale = 10
+	// golden section search
+	ratio := (math.Sqrt(5) - 1) / 2
+	lo, hi := 0.0, float64(maxScale)
+	for hi-lo > 1e-4 {
+		a := hi - ratio*(hi-lo)
+		b := lo + ratio*(hi-lo)
+		if Error(w, a, positions) < Error(w, b, positions) {
+			hi = b
+		} else {
+			lo = a
+		}
+	}
+	return (lo + hi) / 2
+}
+
+type Options struct {
+	// Weights that are not changed, by their name in JSON. The pawn weight is usually
+	// fixed, since the scale of the sigmoid is fitted to the initial weights.
+	Fixed []string
+	// Scale of the sigmoid, fitted to the initial weights with FitScale if zero
+	Scale float64
+	// Change tried in each weight, halved each time none of the changes lowers
+	// the error, until it's under MinStep. 0.1 and 0.001 if zero.
+	Step    float64
+	MinStep float64
+	// Maximum number of passes over the weights, zero means no limit
+	MaxIterations int
+	// Optional, called after each pass with the weights and their error so far
+	Progress func(iteration int, w minimax.Weights, err float64)
+}
+
+type Result struct {
+	Weights minimax.Weights
+	Scale   float64
+	// Error of the initial weights, and of the tuned ones
+	InitialError float64
+	Error        float64
+	Iterations   int
+}
+
+// The float64 fields of the weights, by their name in JSON
+func weightFields(w *minimax.Weights) map[string]*float64 {
+	fields := make(map[string]*float64)
+	v := reflect.ValueOf(w).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("json")
+		fields[name] = v.Field(i).Addr().Interface().(*float64)
+	}
+	return fields
+}
+
+// Names of the weights, in the order they're declared
+func weightNames() []string {
+	t := reflect.TypeOf(minimax.Weights{})
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = t.Field(i).Tag.Get("json")
+	}
+	return names
+}
+
+// Changes one weight at a time by the step, up or down, keeping the changes that lower
+// the error, until no change does so even with the smallest step (a local search).
+func Tune(initial minimax.Weights, positions []Position, opts Options) (Result, error) {
+	if len(positions) == 0 {
+		return Result{}, fmt.Errorf("tuning: no positions")
+	}
+	if opts.Step <= 0 {
+		opts.Step = 0.1
+	}
+	if opts.MinStep <= 0 {
+		opts.MinStep = 0.001
+	}
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = FitScale(initial, positions)
+	}
+
+	w := initial
+	fields := weightFields(&w)
+	fixed := make(map[string]bool)
+	for _, name := range opts.Fixed {
+		if _, ok := fields[name]; !ok {
+			return Result{}, fmt.Errorf("tuning: unknown weight %q", name)
+		}
+		fixed[name] = true
+	}
+	var tuned []*float64
+	for _, name := range weightNames() {
+		if !fixed[name] {
+			tuned = append(tuned, fields[name])
+		}
+	}
+
+	res := Result{Scale: scale, InitialError: Error(w, scale, positions)}
+	best := res.InitialError
+	step := opts.Step
+	for step >= opts.MinStep && (opts.MaxIterations <= 0 || res.Iterations < opts.MaxIterations) {
+		improved := false
+		for _, x := range tuned {
+			old := *x
+			for _, delta := range []float64{step, -step} {
+				*x = old + delta
+				if e := Error(w, scale, positions); e < best {
+					best = e
+					improved = true
+					break
+				}
+				*x = old
+			}
+		}
+		res.Iterations++
+		if opts.Progress != nil {
+			opts.Progress(res.Iterations, w, best)
+		}
+		if !improved {
+			step /= 2
+		}
+	}
+
+	res.Weights = w
+	res.Error = best
+	return res, nil
+}
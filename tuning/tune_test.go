@@ -0,0 +1,100 @@
+package tuning
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/luc527/go_checkers/minimax"
+)
+
+func TestError(t *testing.T) {
+	w := minimax.Weights{Pawn: 1}
+	positions := []Position{
+		{minimax.Weights{Pawn: 0}, 1},
+		{minimax.Weights{Pawn: 0}, 0.5},
+	}
+	// the sigmoid of 0 is 0.5
+	if got := Error(w, 1, positions); got != 0.125 {
+		t.Errorf("wanted error 0.125, got %g", got)
+	}
+	if got := Error(w, 1, nil); got != 0 {
+		t.Errorf("wanted no error without positions, got %g", got)
+	}
+}
+
+func TestFitScale(t *testing.T) {
+	// white wins 3 out of 4 games with a pawn more, so the best sigmoid gives it 0.75
+	var positions []Position
+	for _, result := range []float64{1, 1, 1, 0} {
+		positions = append(positions, Position{minimax.Weights{Pawn: 1}, result})
+		positions = append(positions, Position{minimax.Weights{Pawn: -1}, 1 - result})
+	}
+	want := math.Log(3)
+	if got := FitScale(minimax.Weights{Pawn: 1}, positions); math.Abs(got-want) > 1e-3 {
+		t.Errorf("wanted scale %g, got %g", want, got)
+	}
+}
+
+// Positions whose results follow the true weights: a king is worth 3 pawns, and the center doesn't matter
+func syntheticPositions() []Position {
+	truth := minimax.Weights{Pawn: 1, King: 3}
+	r := rand.New(rand.NewSource(42))
+	var positions []Position
+	for i := 0; i < 2000; i++ {
+		f := minimax.Weights{
+			Pawn:   float64(r.Intn(7) - 3),
+			King:   float64(r.Intn(3) - 1),
+			Center: float64(r.Intn(5) - 2),
+		}
+		result := 0.0
+		if r.Float64() < sigmoid(1, truth.Dot(f)) {
+			result = 1
+		}
+		positions = append(positions, Position{f, result})
+	}
+	return positions
+}
+
+func TestTune(t *testing.T) {
+	positions := syntheticPositions()
+	initial := minimax.Weights{Pawn: 1, King: 1.5, Center: 0.5}
+
+	iterations := 0
+	res, err := Tune(initial, positions, Options{
+		Fixed: []string{"pawn"},
+		Scale: 1,
+		Progress: func(i int, w minimax.Weights, err float64) {
+			iterations = i
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Error >= res.InitialError || res.Scale != 1 || iterations != res.Iterations {
+		t.Errorf("expected the error to go down, got %+v", res)
+	}
+	if res.Weights.Pawn != 1 {
+		t.Errorf("the pawn weight is fixed, got %g", res.Weights.Pawn)
+	}
+	if math.Abs(res.Weights.King-3) > 0.5 || math.Abs(res.Weights.Center) > 0.2 {
+		t.Errorf("expected weights close to the true ones, got %+v", res.Weights)
+	}
+
+	res, err = Tune(initial, positions, Options{MaxIterations: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Iterations != 2 || res.Scale <= 0 {
+		t.Errorf("expected 2 iterations with a fitted scale, got %+v", res)
+	}
+}
+
+func TestTuneErrors(t *testing.T) {
+	if _, err := Tune(minimax.DefaultWeights, nil, Options{}); err == nil {
+		t.Errorf("expected an error without positions")
+	}
+	if _, err := Tune(minimax.DefaultWeights, syntheticPositions(), Options{Fixed: []string{"queen"}}); err == nil {
+		t.Errorf("expected an error for an unknown weight")
+	}
+}